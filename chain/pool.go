@@ -0,0 +1,303 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	ErrUnknownChain = errors.New("chain is not configured")
+	ErrNoEndpoints  = errors.New("no reachable RPC endpoints")
+)
+
+// Options tune how endpoints are health-checked. Zero values fall back to
+// the defaults below.
+type Options struct {
+	// CheckInterval is how often every endpoint is probed.
+	CheckInterval time.Duration
+	// MaxBlockLag is how far an endpoint's head may trail the best head seen
+	// across the pool before it is considered unhealthy.
+	MaxBlockLag uint64
+	// MaxErrorRate is the share of failed probes and calls within the recent
+	// window above which an endpoint is considered unhealthy.
+	MaxErrorRate float64
+	// ErrorWindow is the number of recent probes and calls the error rate is
+	// taken over.
+	ErrorWindow int
+	// MaxConsecutiveFailures is how many live calls in a row may fail before
+	// an endpoint is marked unhealthy without waiting for the next probe.
+	MaxConsecutiveFailures int
+}
+
+const (
+	defaultCheckInterval = 15 * time.Second
+	defaultMaxBlockLag   = 5
+	defaultMaxErrorRate  = 0.5
+	defaultErrorWindow   = 20
+	defaultMaxFailures   = 3
+	probeTimeout         = 5 * time.Second
+)
+
+func (opts Options) withDefaults() Options {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = defaultCheckInterval
+	}
+	if opts.MaxBlockLag == 0 {
+		opts.MaxBlockLag = defaultMaxBlockLag
+	}
+	if opts.MaxErrorRate <= 0 {
+		opts.MaxErrorRate = defaultMaxErrorRate
+	}
+	if opts.ErrorWindow <= 0 {
+		opts.ErrorWindow = defaultErrorWindow
+	}
+	if opts.MaxConsecutiveFailures <= 0 {
+		opts.MaxConsecutiveFailures = defaultMaxFailures
+	}
+	return opts
+}
+
+// endpoint is one RPC URL of a chain and its recent call history.
+type endpoint struct {
+	url     string
+	client  *ethclient.Client
+	head    uint64
+	probes  []bool
+	next    int
+	healthy bool
+	// reported is set for HTTP endpoints, whose every request, probes
+	// included, is recorded by their transport.
+	reported bool
+	failures int
+}
+
+func (e *endpoint) record(ok bool, window int) {
+	if len(e.probes) < window {
+		e.probes = append(e.probes, ok)
+		return
+	}
+	e.probes[e.next] = ok
+	e.next = (e.next + 1) % window
+}
+
+func (e *endpoint) errorRate() float64 {
+	if len(e.probes) == 0 {
+		return 0
+	}
+	var failed int
+	for _, ok := range e.probes {
+		if !ok {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(e.probes))
+}
+
+// Pool holds the clients for every RPC endpoint of a single chain and hands
+// out the currently preferred one. Endpoints are preferred in configuration
+// order; when the current one becomes unhealthy the pool rotates to the next
+// healthy endpoint.
+type Pool struct {
+	chainID   string
+	opts      Options
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	current   int
+}
+
+// NewPool dials every URL. Unreachable URLs are logged and skipped; it is an
+// error for none of them to be reachable.
+func NewPool(chainID string, urls []string, opts Options) (*Pool, error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	pool := &Pool{chainID: chainID, opts: opts.withDefaults()}
+	for _, url := range urls {
+		e := &endpoint{url: url, healthy: true}
+		var options []rpc.ClientOption
+		if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+			e.reported = true
+			options = append(options, rpc.WithHTTPClient(&http.Client{
+				Transport: &reportingTransport{pool: pool, endpoint: e, base: http.DefaultTransport},
+			}))
+		}
+
+		client, err := rpc.DialOptions(context.Background(), url, options...)
+		if err != nil {
+			logger.Error("Failed to connect to chain RPC",
+				slog.String("chain_id", chainID),
+				slog.String("rpc_url", url),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		e.client = ethclient.NewClient(client)
+		pool.endpoints = append(pool.endpoints, e)
+	}
+	if len(pool.endpoints) == 0 {
+		return nil, fmt.Errorf("%w for chain %s", ErrNoEndpoints, chainID)
+	}
+	return pool, nil
+}
+
+// Client returns the client of the currently preferred endpoint.
+func (p *Pool) Client() *ethclient.Client {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.endpoints[p.current].client
+}
+
+// Check probes every endpoint once and rotates away from the current endpoint
+// if it is no longer healthy.
+func (p *Pool) Check(ctx context.Context) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("chain_id", p.chainID),
+	)
+
+	heads := make([]uint64, len(p.endpoints))
+	errs := make([]error, len(p.endpoints))
+
+	var wg sync.WaitGroup
+	for i, e := range p.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+			defer cancel()
+			heads[i], errs[i] = e.client.BlockNumber(probeCtx)
+		}(i, e)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best uint64
+	for i, e := range p.endpoints {
+		if !e.reported {
+			e.record(errs[i] == nil, p.opts.ErrorWindow)
+		}
+		if errs[i] == nil {
+			e.head = heads[i]
+			best = max(best, e.head)
+		}
+	}
+
+	for i, e := range p.endpoints {
+		healthy := errs[i] == nil &&
+			e.head+p.opts.MaxBlockLag >= best &&
+			e.errorRate() <= p.opts.MaxErrorRate
+		if healthy != e.healthy {
+			logger.Warn("RPC endpoint health changed",
+				slog.String("rpc_url", e.url),
+				slog.Bool("healthy", healthy),
+				slog.Uint64("head", e.head),
+				slog.Uint64("best_head", best),
+				slog.Float64("error_rate", e.errorRate()),
+				slog.Any("error", errs[i]),
+			)
+		}
+		e.healthy = healthy
+	}
+
+	p.rotate(logger)
+}
+
+// rotate moves to the next healthy endpoint if the current one is unhealthy.
+// The caller holds p.mu.
+func (p *Pool) rotate(logger *slog.Logger) {
+	if p.endpoints[p.current].healthy {
+		return
+	}
+	for step := 1; step < len(p.endpoints); step++ {
+		next := (p.current + step) % len(p.endpoints)
+		if p.endpoints[next].healthy {
+			logger.Warn("Rotating RPC endpoint",
+				slog.String("from", p.endpoints[p.current].url),
+				slog.String("to", p.endpoints[next].url),
+			)
+			p.current = next
+			return
+		}
+	}
+}
+
+// report records the outcome of a live call to e. After too many failures in
+// a row e is marked unhealthy and the pool rotates away from it; the next
+// successful probe restores it.
+func (p *Pool) report(e *endpoint, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e.record(ok, p.opts.ErrorWindow)
+	if ok {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures < p.opts.MaxConsecutiveFailures || !e.healthy {
+		return
+	}
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("chain_id", p.chainID),
+	)
+	logger.Warn("RPC endpoint health changed",
+		slog.String("rpc_url", e.url),
+		slog.Bool("healthy", false),
+		slog.Int("consecutive_failures", e.failures),
+	)
+	e.healthy = false
+	p.rotate(logger)
+}
+
+// reportingTransport reports the outcome of every request an endpoint's
+// client sends to the pool. Transport errors, rate limiting and server errors
+// count as failures; JSON-RPC errors such as a reverted call do not, since
+// they arrive in successful responses.
+type reportingTransport struct {
+	pool     *Pool
+	endpoint *endpoint
+	base     http.RoundTripper
+}
+
+func (t *reportingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if errors.Is(err, context.Canceled) {
+		// The caller gave up; that says nothing about the endpoint.
+		return resp, err
+	}
+	ok := err == nil && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500
+	t.pool.report(t.endpoint, ok)
+	return resp, err
+}
+
+func (p *Pool) run(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Check(ctx)
+		}
+	}
+}
+
+// Close closes every endpoint's client.
+func (p *Pool) Close() {
+	for _, e := range p.endpoints {
+		e.client.Close()
+	}
+}
@@ -0,0 +1,63 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPoolRotatesAfterFailedCalls(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	defer healthy.Close()
+
+	pool, err := NewPool("1", []string{failing.URL, healthy.URL}, Options{MaxConsecutiveFailures: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := pool.Client().BlockNumber(context.Background()); err == nil {
+			t.Fatalf("call %d to the failing endpoint succeeded", i)
+		}
+	}
+
+	head, err := pool.Client().BlockNumber(context.Background())
+	if err != nil {
+		t.Fatalf("pool did not rotate to the healthy endpoint: %v", err)
+	}
+	if head != 16 {
+		t.Fatalf("head = %d, want 16", head)
+	}
+}
+
+func TestPoolKeepsEndpointOnRPCErrors(t *testing.T) {
+	reverting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`))
+	}))
+	defer reverting.Close()
+
+	pool, err := NewPool("1", []string{reverting.URL, "http://127.0.0.1:1"}, Options{MaxConsecutiveFailures: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	first := pool.Client()
+	for i := 0; i < 3; i++ {
+		pool.Client().BlockNumber(context.Background())
+	}
+	if pool.Client() != first {
+		t.Fatal("pool rotated away from an endpoint that only returned JSON-RPC errors")
+	}
+}
@@ -0,0 +1,69 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Registry is the long-lived set of RPC pools for every configured chain,
+// shared by all requests of a service.
+type Registry struct {
+	pools  map[string]*Pool
+	cancel context.CancelFunc
+}
+
+// NewRegistry builds a pool per chain from chainURLs, keyed by chain ID, and
+// starts health-checking them in the background until Close is called.
+// Chains without a reachable endpoint are logged and left out.
+func NewRegistry(chainURLs map[string][]string, opts Options) *Registry {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	registry := &Registry{
+		pools:  make(map[string]*Pool),
+		cancel: cancel,
+	}
+	for chainID, urls := range chainURLs {
+		pool, err := NewPool(chainID, urls, opts)
+		if err != nil {
+			logger.Error("Failed to create RPC pool",
+				slog.String("chain_id", chainID),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		registry.pools[chainID] = pool
+		go pool.run(ctx)
+	}
+	return registry
+}
+
+// Pool returns the pool for chainID.
+func (r *Registry) Pool(chainID string) (*Pool, error) {
+	pool, ok := r.pools[chainID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownChain, chainID)
+	}
+	return pool, nil
+}
+
+// Client returns the preferred client for chainID.
+func (r *Registry) Client(chainID string) (*ethclient.Client, error) {
+	pool, err := r.Pool(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return pool.Client(), nil
+}
+
+// Close stops health checks and closes every client.
+func (r *Registry) Close() {
+	r.cancel()
+	for _, pool := range r.pools {
+		pool.Close()
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/queue"
+	"github.com/Dev317/golang_wallet/respond"
+)
+
+const (
+	defaultDeadLetterLimit = 20
+	maxDeadLetterLimit     = 500
+)
+
+// adminQueues are the queues whose dead letters may be inspected and replayed.
+var adminQueues = map[string]bool{
+	event.ScanQueue:    true,
+	event.ResultQueue:  true,
+	event.DepositQueue: true,
+}
+
+type ListDeadLettersResponse struct {
+	Queue       string             `json:"queue"`
+	DeadLetters []queue.DeadLetter `json:"dead_letters"`
+}
+
+type ReplayDeadLettersResponse struct {
+	Queue    string `json:"queue"`
+	Replayed int    `json:"replayed"`
+}
+
+// requireAdmin only lets requests through that carry the configured admin
+// token as a bearer token. Without a configured token admin routes are off.
+func (server *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		expected := server.config.AdminToken
+		if expected == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respond.WriteError(w, http.StatusForbidden, respond.CodeForbidden, "Forbidden")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseDeadLetterQuery reads ?queue= and ?limit=, writing the error response
+// itself on failure.
+func parseDeadLetterQuery(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	query := r.URL.Query()
+	name := query.Get("queue")
+	if !adminQueues[name] {
+		respond.Invalid(w, []respond.FieldError{{Field: "queue", Message: "unknown queue"}})
+		return "", 0, false
+	}
+
+	limit := defaultDeadLetterLimit
+	if raw := query.Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			respond.Invalid(w, []respond.FieldError{{Field: "limit", Message: "must be a positive integer"}})
+			return "", 0, false
+		}
+		limit = min(v, maxDeadLetterLimit)
+	}
+	return name, limit, true
+}
+
+// ListDeadLetters shows messages in a queue's dead-letter queue without
+// removing them.
+func (server *Server) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	name, limit, ok := parseDeadLetterQuery(w, r)
+	if !ok {
+		return
+	}
+
+	letters, err := server.bus.PeekDeadLetters(name, limit)
+	if err != nil {
+		respond.Internal(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, ListDeadLettersResponse{Queue: name, DeadLetters: letters})
+}
+
+// ReplayDeadLetters moves messages from a queue's dead-letter queue back onto
+// the queue for another round of processing.
+func (server *Server) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	name, limit, ok := parseDeadLetterQuery(w, r)
+	if !ok {
+		return
+	}
+
+	replayed, err := server.bus.ReplayDeadLetters(r.Context(), name, limit)
+	if err != nil {
+		respond.Internal(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, ReplayDeadLettersResponse{Queue: name, Replayed: replayed})
+}
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Dev317/golang_wallet/chain"
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/store"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Deposit statuses, stored in deposits.status.
+const (
+	DepositStatusDetected  = "detected"
+	DepositStatusConfirmed = "confirmed"
+	DepositStatusReverted  = "reverted"
+)
+
+var errReorgTooDeep = errors.New("reorg deeper than configured REORG_DEPTH")
+
+// transferTopic is the signature topic of the ERC-20 Transfer event.
+var transferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// nativeLogIndex is the log index recorded for native transfers, which are
+// not logged.
+const nativeLogIndex = -1
+
+// depositEventTypes maps each deposit status to the event announcing it.
+var depositEventTypes = map[string]string{
+	DepositStatusDetected:  event.TypeDepositDetected,
+	DepositStatusConfirmed: event.TypeDepositConfirmed,
+	DepositStatusReverted:  event.TypeDepositReverted,
+}
+
+// FollowBlocks walks new blocks on a chain from the persisted cursor, records
+// native transfers and ERC-20 Transfer events into our accounts as deposits,
+// and confirms them once they are buried under the configured number of
+// blocks.
+func (server *Server) FollowBlocks(chainID string) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("chain_id", chainID),
+	)
+
+	dbChainID, err := strconv.ParseInt(chainID, 10, 32)
+	if err != nil {
+		logger.Error("Invalid chain ID", slog.Any("error", err))
+		return
+	}
+
+	pool, err := server.clients.Pool(chainID)
+	if err != nil {
+		logger.Error("No RPC client configured for chain", slog.Any("error", err))
+		return
+	}
+
+	follower := &blockFollower{
+		server:  server,
+		pool:    pool,
+		chainID: chainID,
+		dbChain: int32(dbChainID),
+	}
+
+	ticker := time.NewTicker(server.config.PollInterval)
+	defer ticker.Stop()
+
+	logger.Info("Following blocks")
+	for range ticker.C {
+		err := follower.catchUp(context.Background())
+		if err != nil {
+			logger.Error("Failed to scan blocks", slog.Any("error", err))
+		}
+	}
+}
+
+// blockFollower scans a single chain.
+type blockFollower struct {
+	server  *Server
+	pool    *chain.Pool
+	client  *ethclient.Client
+	chainID string
+	dbChain int32
+}
+
+// catchUp scans every block between the cursor and the current head,
+// rewinding to the fork point whenever a reorg is detected.
+func (f *blockFollower) catchUp(ctx context.Context) error {
+	f.client = f.pool.Client()
+
+	head, err := f.client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := f.server.q.GetScanCursor(ctx, f.dbChain)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Start from the current head rather than replaying the whole chain.
+		header, err := f.client.HeaderByNumber(ctx, new(big.Int).SetUint64(head))
+		if err != nil {
+			return err
+		}
+		return f.server.store.ExecTx(ctx, func(q *db.Queries) error {
+			return f.recordBlock(ctx, q, head, header.Hash(), header.ParentHash)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	for n := uint64(cursor.BlockNumber) + 1; n <= head; n++ {
+		fork, err := f.scanBlock(ctx, n)
+		if err != nil {
+			return err
+		}
+		if fork != nil {
+			// Resume from the block after the fork point on the next pass.
+			n = *fork
+		}
+	}
+
+	return f.confirmDeposits(ctx, head)
+}
+
+// recordBlock stores the block hash for parent checks and advances the cursor.
+func (f *blockFollower) recordBlock(ctx context.Context, q *db.Queries, number uint64, hash common.Hash, parentHash common.Hash) error {
+	err := q.CreateScannedBlock(ctx, db.CreateScannedBlockParams{
+		ChainID:     f.dbChain,
+		BlockNumber: int64(number),
+		BlockHash:   hash.Hex(),
+		ParentHash:  parentHash.Hex(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if depth := f.server.config.ReorgDepth; number > depth {
+		err = q.PruneScannedBlocks(ctx, db.PruneScannedBlocksParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(number - depth),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return q.UpsertScanCursor(ctx, db.UpsertScanCursorParams{
+		ChainID:     f.dbChain,
+		BlockNumber: int64(number),
+		BlockHash:   hash.Hex(),
+	})
+}
+
+// scanBlock records deposits in block number. If the block does not build on
+// the block we scanned before it, the chain reorganized: scanBlock rolls back
+// to the fork point and returns it instead.
+func (f *blockFollower) scanBlock(ctx context.Context, number uint64) (*uint64, error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	block, err := f.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := f.server.q.GetScannedBlock(ctx, db.GetScannedBlockParams{
+		ChainID:     f.dbChain,
+		BlockNumber: int64(number - 1),
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil && parent.BlockHash != block.ParentHash().Hex() {
+		logger.Warn("Chain reorganization detected",
+			slog.String("chain_id", f.chainID),
+			slog.Uint64("block_number", number),
+			slog.String("expected_parent", parent.BlockHash),
+			slog.String("actual_parent", block.ParentHash().Hex()),
+		)
+		fork, err := f.findForkPoint(ctx, number-1)
+		if err != nil {
+			return nil, err
+		}
+		return &fork, f.rollback(ctx, fork)
+	}
+
+	// Filtering by block hash pins the logs to the block scanned above even if
+	// the chain reorganizes in between.
+	blockHash := block.Hash()
+	logs, err := f.client.FilterLogs(ctx, ethereum.FilterQuery{
+		BlockHash: &blockHash,
+		Topics:    [][]common.Hash{{transferTopic}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := blockTransfers(block, logs)
+	addresses := make([]string, 0, len(transfers))
+	for _, transfer := range transfers {
+		addresses = append(addresses, transfer.To.Hex())
+	}
+
+	var deposits []db.Deposit
+	err = f.server.store.ExecTx(ctx, func(q *db.Queries) error {
+		if len(addresses) > 0 {
+			accounts, err := q.ListAccountsByAddresses(ctx, db.ListAccountsByAddressesParams{
+				ChainID:   f.dbChain,
+				Addresses: addresses,
+			})
+			if err != nil {
+				return err
+			}
+
+			byAddress := make(map[string]db.Account, len(accounts))
+			for _, account := range accounts {
+				byAddress[account.Address] = account
+			}
+
+			for _, transfer := range transfers {
+				account, ok := byAddress[transfer.To.Hex()]
+				if !ok {
+					continue
+				}
+
+				var tokenAddress pgtype.Text
+				if transfer.Token != nil {
+					tokenAddress = pgtype.Text{String: transfer.Token.Hex(), Valid: true}
+				}
+				deposit, err := q.CreateDeposit(ctx, db.CreateDepositParams{
+					AccountID:    account.ID,
+					ChainID:      f.dbChain,
+					TxHash:       transfer.TxHash.Hex(),
+					FromAddress:  transfer.From.Hex(),
+					ToAddress:    account.Address,
+					Value:        pgtype.Numeric{Int: transfer.Value, Valid: true},
+					BlockNumber:  int64(number),
+					BlockHash:    blockHash.Hex(),
+					TokenAddress: tokenAddress,
+					LogIndex:     transfer.LogIndex,
+				})
+				if errors.Is(err, pgx.ErrNoRows) {
+					// Already recorded on this chain of blocks.
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				err = f.enqueueDeposit(ctx, q, deposit, "")
+				if err != nil {
+					return err
+				}
+				deposits = append(deposits, deposit)
+			}
+		}
+
+		return f.recordBlock(ctx, q, number, blockHash, block.ParentHash())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deposit := range deposits {
+		logger.Info("Deposit detected",
+			slog.String("chain_id", f.chainID),
+			slog.String("tx_hash", deposit.TxHash),
+			slog.String("to_address", deposit.ToAddress),
+			slog.String("token_address", deposit.TokenAddress.String),
+		)
+	}
+	return nil, nil
+}
+
+// findForkPoint walks back from number until the stored block hash matches the
+// canonical chain again, giving the highest block both chains share.
+func (f *blockFollower) findForkPoint(ctx context.Context, number uint64) (uint64, error) {
+	depth := f.server.config.ReorgDepth
+	for n := number; n > 0 && number-n < depth; n-- {
+		stored, err := f.server.q.GetScannedBlock(ctx, db.GetScannedBlockParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(n),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		header, err := f.client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return 0, err
+		}
+		if header.Hash().Hex() == stored.BlockHash {
+			return n, nil
+		}
+	}
+	return 0, errReorgTooDeep
+}
+
+// rollback reverts every deposit and transaction confirmation recorded above
+// the fork point and rewinds the cursor so those blocks are scanned again.
+// Compensating events are written to the outbox in the same transaction so
+// consumers can undo credits.
+func (f *blockFollower) rollback(ctx context.Context, fork uint64) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	now := time.Now().UTC()
+	var deposits []db.Deposit
+	var txs []db.Transaction
+	var retrack []TransactionEvent
+	err := f.server.store.ExecTx(ctx, func(q *db.Queries) error {
+		forkBlock, err := q.GetScannedBlock(ctx, db.GetScannedBlockParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(fork),
+		})
+		if err != nil {
+			return err
+		}
+
+		deposits, err = q.RevertDepositsAbove(ctx, db.RevertDepositsAboveParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(fork),
+		})
+		if err != nil {
+			return err
+		}
+
+		txs, err = q.ListTransactionsInBlocksAbove(ctx, db.ListTransactionsInBlocksAboveParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(fork),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, deposit := range deposits {
+			balance := f.depositBalance(ctx, deposit)
+			err := f.enqueueDeposit(ctx, q, deposit, balance)
+			if err != nil {
+				return err
+			}
+		}
+		for _, tx := range txs {
+			err := enqueueResult(ctx, q, &TransactionResultEvent{
+				TransactionID:   tx.ID,
+				TransactionHash: tx.Hash.String,
+				ChainID:         f.chainID,
+				FromAddress:     tx.FromAddress,
+				CorrelationID:   event.TransactionCorrelationID(tx.ID),
+				Status:          ResultStatusReverted,
+			})
+			if err != nil {
+				return err
+			}
+
+			tracked := transactionTrackedEvent(tx, now)
+			err = restartTracking(ctx, q, tracked)
+			if err != nil {
+				return err
+			}
+			retrack = append(retrack, tracked)
+		}
+
+		err = q.DeleteScannedBlocksAbove(ctx, db.DeleteScannedBlocksAboveParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(fork),
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.UpsertScanCursor(ctx, db.UpsertScanCursorParams{
+			ChainID:     f.dbChain,
+			BlockNumber: int64(fork),
+			BlockHash:   forkBlock.BlockHash,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Warn("Rolled back to fork point",
+		slog.String("chain_id", f.chainID),
+		slog.Uint64("fork_block", fork),
+		slog.Int("reverted_deposits", len(deposits)),
+		slog.Int("reverted_transactions", len(txs)),
+	)
+
+	// Watch the transactions again until they are re-mined or dropped.
+	for _, tracked := range retrack {
+		go f.server.trackTransaction(tracked, f.pool)
+	}
+	return nil
+}
+
+// confirmDeposits promotes detected deposits that have enough confirmations.
+func (f *blockFollower) confirmDeposits(ctx context.Context, head uint64) error {
+	confirmations := f.server.config.Confirmations
+	if head+1 < confirmations {
+		return nil
+	}
+
+	pending, err := f.server.q.ListDepositsToConfirm(ctx, db.ListDepositsToConfirmParams{
+		ChainID:     f.dbChain,
+		BlockNumber: int64(head + 1 - confirmations),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, deposit := range pending {
+		balance := f.depositBalance(ctx, deposit)
+		err := f.server.store.ExecTx(ctx, func(q *db.Queries) error {
+			confirmed, err := q.MarkDepositConfirmed(ctx, deposit.ID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return f.enqueueDeposit(ctx, q, confirmed, balance)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueueDeposit writes the event for deposit's current status to the
+// outbox, with the recipient's balance if known.
+func (f *blockFollower) enqueueDeposit(ctx context.Context, q *db.Queries, deposit db.Deposit, balance string) error {
+	envelope, err := event.New(
+		depositEventTypes[deposit.Status],
+		f.chainID,
+		event.DepositCorrelationID(deposit.ID),
+		&event.DepositDetected{
+			DepositID:    deposit.ID,
+			AccountID:    deposit.AccountID,
+			TxHash:       deposit.TxHash,
+			FromAddress:  deposit.FromAddress,
+			ToAddress:    deposit.ToAddress,
+			Value:        store.NumericString(deposit.Value),
+			BlockNumber:  uint64(deposit.BlockNumber),
+			Balance:      balance,
+			TokenAddress: deposit.TokenAddress.String,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return store.Enqueue(ctx, q, outboxProducer, event.DepositQueue, envelope)
+}
+
+// depositBalance returns the recipient's native balance for a native deposit.
+// Token deposits carry no balance.
+func (f *blockFollower) depositBalance(ctx context.Context, deposit db.Deposit) string {
+	if deposit.TokenAddress.Valid {
+		return ""
+	}
+	return balanceOf(ctx, f.client, common.HexToAddress(deposit.ToAddress))
+}
+
+// transfer is a movement of value found in a block: a native transfer, or an
+// ERC-20 Transfer event when Token is set.
+type transfer struct {
+	TxHash   common.Hash
+	LogIndex int32
+	From     common.Address
+	To       common.Address
+	Value    *big.Int
+	Token    *common.Address
+}
+
+// blockTransfers returns the native transfers in block followed by the ERC-20
+// transfers among its logs. Logs that are not a standard Transfer event, such
+// as ERC-721 transfers with an indexed token ID, are skipped.
+func blockTransfers(block *types.Block, logs []types.Log) []transfer {
+	var transfers []transfer
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || tx.Value().Sign() == 0 {
+			continue
+		}
+		transfers = append(transfers, transfer{
+			TxHash:   tx.Hash(),
+			LogIndex: nativeLogIndex,
+			From:     senderOf(tx),
+			To:       *tx.To(),
+			Value:    tx.Value(),
+		})
+	}
+
+	for _, log := range logs {
+		if log.Removed || len(log.Topics) != 3 || log.Topics[0] != transferTopic || len(log.Data) != 32 {
+			continue
+		}
+		value := new(big.Int).SetBytes(log.Data)
+		if value.Sign() == 0 {
+			continue
+		}
+		token := log.Address
+		transfers = append(transfers, transfer{
+			TxHash:   log.TxHash,
+			LogIndex: int32(log.Index),
+			From:     common.BytesToAddress(log.Topics[1].Bytes()),
+			To:       common.BytesToAddress(log.Topics[2].Bytes()),
+			Value:    value,
+			Token:    &token,
+		})
+	}
+	return transfers
+}
+
+// senderOf recovers the sender of a mined transaction, returning the zero
+// address if the signature cannot be recovered.
+func senderOf(tx *types.Transaction) common.Address {
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}
+	}
+	return from
+}
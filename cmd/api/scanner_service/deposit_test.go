@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestBlockTransfers(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000000b0")
+	token := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	sign := func(nonce uint64, to *common.Address, value int64) *types.Transaction {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       to,
+			Value:    big.NewInt(value),
+			Gas:      21000,
+			GasPrice: big.NewInt(1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	native := sign(0, &recipient, 5)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)}).WithBody([]*types.Transaction{
+		native,
+		sign(1, &recipient, 0), // contract call without value
+		sign(2, nil, 7),        // contract creation
+	}, nil)
+
+	amount := common.BigToHash(big.NewInt(1000)).Bytes()
+	topics := []common.Hash{transferTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(recipient.Bytes())}
+	tokenTx := common.HexToHash("0x01")
+	logs := []types.Log{
+		{Address: token, Topics: topics, Data: amount, TxHash: tokenTx, Index: 3},
+		{Address: token, Topics: topics, Data: amount, TxHash: tokenTx, Index: 4, Removed: true},
+		{Address: token, Topics: append(topics, common.Hash{}), TxHash: tokenTx, Index: 5},
+		{Address: token, Topics: topics, Data: common.Hash{}.Bytes(), TxHash: tokenTx, Index: 6},
+		{Address: token, Topics: []common.Hash{common.HexToHash("0x02"), topics[1], topics[2]}, Data: amount, TxHash: tokenTx, Index: 7},
+	}
+
+	got := blockTransfers(block, logs)
+	want := []transfer{
+		{TxHash: native.Hash(), LogIndex: nativeLogIndex, From: sender, To: recipient, Value: big.NewInt(5)},
+		{TxHash: tokenTx, LogIndex: 3, From: sender, To: recipient, Value: big.NewInt(1000), Token: &token},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d transfers, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.TxHash != w.TxHash || g.LogIndex != w.LogIndex || g.From != w.From || g.To != w.To || g.Value.Cmp(w.Value) != 0 {
+			t.Errorf("transfer %d = %+v, want %+v", i, g, w)
+		}
+		if (g.Token == nil) != (w.Token == nil) || (g.Token != nil && *g.Token != *w.Token) {
+			t.Errorf("transfer %d token = %v, want %v", i, g.Token, w.Token)
+		}
+	}
+}
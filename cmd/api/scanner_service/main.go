@@ -18,6 +18,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	ethConfig, err := cf.LoadEthereumConfig(".")
+	if err != nil {
+		logger.Error("Failed to load ethereum config",
+			slog.Any("error", err),
+		)
+		os.Exit(1)
+	}
+
 	queueConfig, err := cf.LoadQueueConfig(".")
 	if err != nil {
 		logger.Error("Failed to load queue config",
@@ -26,6 +34,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	server := NewServer(config, queueConfig)
+	server := NewServer(config, ethConfig, queueConfig)
 	server.Start()
-}
\ No newline at end of file
+}
@@ -2,22 +2,39 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Dev317/golang_wallet/chain"
 	cf "github.com/Dev317/golang_wallet/config/scanner"
-	amqp "github.com/rabbitmq/amqp091-go"
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/queue"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
 )
 
+// outboxProducer tags this service's outbox rows; the wallet relays its own
+// from the same table.
+const outboxProducer = "scanner_service"
+
 type Server struct {
-	config    cf.Config
+	config      cf.Config
+	ethConfig   cf.EthereumConfig
 	queueConfig cf.QueueConfig
-	s         *http.Server
-	queueConn *amqp.Connection
+	store       *store.Store
+	q           *db.Queries
+	s           *http.Server
+	bus         queue.Bus
+	clients     *chain.Registry
+	// tracking holds the IDs of transactions with a running tracker.
+	tracking sync.Map
 }
 
 func makeHTTPServer(config cf.Config) *http.Server {
@@ -29,35 +46,26 @@ func makeHTTPServer(config cf.Config) *http.Server {
 	}
 }
 
-func makeQueueConnection(queueConfig cf.QueueConfig) (*amqp.Connection, error) {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	conn, err := amqp.Dial(queueConfig.URI)
-	if err != nil {
-		logger.Error("Failed to connect to RabbitMQ",
-			slog.Any("error", err),
-		)
-		return nil, err
-	}
-	return conn, nil
+// makeBus opens the message bus named by the queue URI. For RabbitMQ the
+// connection is kept alive in the background and the queues this service
+// publishes to and consumes from are declared on every reconnect.
+func makeBus(queueConfig cf.QueueConfig) queue.Bus {
+	return queue.Open(queueConfig.URI, event.ScanQueue, event.ResultQueue, event.DepositQueue)
 }
 
-func NewServer(config cf.Config, queueConfig cf.QueueConfig) *Server {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+func NewServer(config cf.Config, ethConfig cf.EthereumConfig, queueConfig cf.QueueConfig) *Server {
+	database := store.Open(config.Database())
 	s := makeHTTPServer(config)
 
-	queueConn, err := makeQueueConnection(queueConfig)
-	if err != nil {
-		logger.Error("Failed to connect to RabbitMQ",
-			slog.Any("error", err),
-		)
-		os.Exit(1)
-	}
-	logger.Info("Connected to RabbitMQ")
-
 	server := &Server{
-		config:    config,
-		s:         s,
-		queueConn: queueConn,
+		config:      config,
+		ethConfig:   ethConfig,
+		queueConfig: queueConfig,
+		store:       database,
+		q:           database.Queries(),
+		s:           s,
+		bus:         makeBus(queueConfig),
+		clients:     chain.NewRegistry(ethConfig.ChainURLs(), config.RPCOptions()),
 	}
 
 	mux := http.NewServeMux()
@@ -66,70 +74,66 @@ func NewServer(config cf.Config, queueConfig cf.QueueConfig) *Server {
 }
 
 func (server *Server) SetupRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/api/v1/health-check", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("message: Service is healthy!"))
-	})
+	admin := http.NewServeMux()
+	admin.HandleFunc("/dead_letters", server.ListDeadLetters)
+	admin.HandleFunc("/replay_dead_letters", server.ReplayDeadLetters)
+	mux.Handle("/api/v1/admin/", http.StripPrefix("/api/v1/admin", server.requireAdmin(admin)))
+
+	mux.HandleFunc("/api/v1/health-check", server.HealthCheck)
 
 	server.s.Handler = mux
 }
 
+// Consume starts tracking every transaction announced on queueName. A
+// message is acknowledged once tracking is recorded, so a restart resumes it.
+// The subscription survives reconnects to the broker.
 func (server *Server) Consume(queueName string) {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	ch, err := server.queueConn.Channel()
-	if err != nil {
-		logger.Error("Failed to open a channel",
-			slog.Any("error", err),
-		)
-		return
-	}
-	defer ch.Close()
-
-	q, err := ch.QueueDeclare(
-		queueName, // name
-		false,   // durable
-		false,   // delete when unused
-		false,   // exclusive
-		false,   // no-wait
-		nil,     // arguments
-	)
+	server.bus.Subscribe(queueName, queue.DefaultPrefetch, queue.DefaultRetryPolicy, server.handleTransactionEvent)
+}
 
+func (server *Server) handleTransactionEvent(ctx context.Context, body []byte) error {
+	envelope, payload, err := event.Parse(body)
 	if err != nil {
-		logger.Error("Failed to declare a queue",
-			slog.Any("error", err),
-		)
-		return
+		return queue.Permanent(err)
+	}
+	submitted, ok := payload.(*event.TransactionSubmitted)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("%w: %s on %s", event.ErrUnknownType, envelope.Type, event.ScanQueue))
 	}
 
-	msgs, err := ch.Consume(
-		q.Name, // queue
-		"",     // consumer
-		true,   // auto-ack
-		false,  // exclusive
-		false,  // no-local
-		false,  // no-wait
-		nil,    // args
-	)
-
+	pool, err := server.clients.Pool(envelope.ChainID)
 	if err != nil {
-		logger.Error("Failed to consume messages",
-			slog.Any("error", err),
-		)
-		return
+		return queue.Permanent(err)
 	}
 
-	var forever chan struct{}
+	tracked := TransactionEvent{
+		TransactionID:   submitted.TransactionID,
+		TransactionHash: submitted.TransactionHash,
+		ChainID:         envelope.ChainID,
+		FromAddress:     submitted.FromAddress,
+		ToAddress:       submitted.ToAddress,
+		Nonce:           submitted.Nonce,
+		CorrelationID:   envelope.CorrelationID,
+		StartedAt:       time.Now().UTC(),
+	}
 
-	go func() {
-		for d := range msgs {
-			logger.Info("Received a message",
-				slog.Any("message", string(d.Body)),
-			)
+	var fresh bool
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		fresh, err = store.MarkProcessed(ctx, q, event.ScanQueue, envelope.ID)
+		if err != nil || !fresh {
+			return err
 		}
-	}()
+		return startTracking(ctx, q, tracked)
+	})
+	if err != nil {
+		return err
+	}
 
-	logger.Info(" [*] Waiting for messages")
-	<-forever
+	if fresh {
+		go server.trackTransaction(tracked, pool)
+	}
+	return nil
 }
 
 func (server *Server) Start() {
@@ -147,7 +151,21 @@ func (server *Server) Start() {
 	}()
 	logger.Info("Server started successfully")
 
-	go server.Consume("scan_queue")
+	resumeCtx, cancelResume := context.WithTimeout(context.Background(), 30*time.Second)
+	err := server.ResumeTracking(resumeCtx)
+	cancelResume()
+	if err != nil {
+		logger.Error("Failed to resume tracking transactions",
+			slog.Any("error", err),
+		)
+	}
+
+	go server.Consume(event.ScanQueue)
+	go server.store.RelayOutbox(server.bus, outboxProducer)
+
+	for _, chainItem := range server.ethConfig.ChainItemList {
+		go server.FollowBlocks(chainItem.ChainID)
+	}
 
 	<-done
 	logger.Warn("Server stopped!")
@@ -159,8 +177,26 @@ func (server *Server) Start() {
 		logger.Error("Failed to shutdown server",
 			slog.Any("error", err),
 		)
-		server.queueConn.Close()
 	}
+	server.bus.Close()
+	server.clients.Close()
 
 	logger.Warn("Server shutdown successfully")
 }
+
+type HealthResponse struct {
+	Message string `json:"message"`
+	Queue   string `json:"queue"`
+}
+
+// HealthCheck reports 503 while the message bus is down, since tracked
+// transactions and deposits cannot be reported until it is back.
+func (server *Server) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	state := server.bus.State()
+	if state != queue.StateConnected {
+		respond.JSON(w, http.StatusServiceUnavailable, HealthResponse{Message: "Service is degraded!", Queue: state})
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, HealthResponse{Message: "Service is healthy!", Queue: state})
+}
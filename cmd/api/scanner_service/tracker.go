@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Dev317/golang_wallet/chain"
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Result statuses reported back to the wallet service.
+const (
+	ResultStatusMined     = "mined"
+	ResultStatusConfirmed = "confirmed"
+	ResultStatusFailed    = "failed"
+	ResultStatusDropped   = "dropped"
+	ResultStatusReverted  = "reverted"
+	ResultStatusReplaced  = "replaced"
+)
+
+// TransactionEvent identifies a submitted transaction to track, along with
+// the tracking state persisted in tracked_transactions.
+type TransactionEvent struct {
+	TransactionID   int64
+	TransactionHash string
+	ChainID         string
+	FromAddress     string
+	ToAddress       string
+	Nonce           uint64
+	CorrelationID   string
+	StartedAt       time.Time
+	MinedBlockHash  string
+}
+
+// transactionTrackedEvent returns the tracking details of a transaction
+// recorded by the wallet. Token transfers are sent to the token contract.
+func transactionTrackedEvent(tx db.Transaction, startedAt time.Time) TransactionEvent {
+	toAddress := tx.ToAddress
+	if tx.TokenAddress.Valid {
+		toAddress = tx.TokenAddress.String
+	}
+	return TransactionEvent{
+		TransactionID:   tx.ID,
+		TransactionHash: tx.Hash.String,
+		ChainID:         strconv.Itoa(int(tx.ChainID)),
+		FromAddress:     tx.FromAddress,
+		ToAddress:       toAddress,
+		Nonce:           uint64(tx.Nonce.Int64),
+		CorrelationID:   event.TransactionCorrelationID(tx.ID),
+		StartedAt:       startedAt,
+	}
+}
+
+func trackedEventFromRow(row db.TrackedTransaction) TransactionEvent {
+	return TransactionEvent{
+		TransactionID:   row.TransactionID,
+		TransactionHash: row.TxHash,
+		ChainID:         strconv.Itoa(int(row.ChainID)),
+		FromAddress:     row.FromAddress,
+		ToAddress:       row.ToAddress,
+		Nonce:           uint64(row.Nonce),
+		CorrelationID:   row.CorrelationID,
+		StartedAt:       row.StartedAt.Time,
+		MinedBlockHash:  row.MinedBlockHash.String,
+	}
+}
+
+// trackingParams returns the row that records tracked as being tracked.
+func trackingParams(tracked TransactionEvent) (db.StartTrackingTransactionParams, error) {
+	chainID, err := strconv.ParseInt(tracked.ChainID, 10, 32)
+	if err != nil {
+		return db.StartTrackingTransactionParams{}, err
+	}
+	return db.StartTrackingTransactionParams{
+		TransactionID: tracked.TransactionID,
+		ChainID:       int32(chainID),
+		TxHash:        tracked.TransactionHash,
+		FromAddress:   tracked.FromAddress,
+		ToAddress:     tracked.ToAddress,
+		Nonce:         int64(tracked.Nonce),
+		CorrelationID: tracked.CorrelationID,
+		StartedAt:     pgtype.Timestamp{Time: tracked.StartedAt, Valid: true},
+	}, nil
+}
+
+// startTracking records tracked unless it is tracked already.
+func startTracking(ctx context.Context, q *db.Queries, tracked TransactionEvent) error {
+	params, err := trackingParams(tracked)
+	if err != nil {
+		return err
+	}
+	return q.StartTrackingTransaction(ctx, params)
+}
+
+// restartTracking records tracked afresh, forgetting any earlier progress,
+// e.g. after the block it was mined in was reorganized away.
+func restartTracking(ctx context.Context, q *db.Queries, tracked TransactionEvent) error {
+	params, err := trackingParams(tracked)
+	if err != nil {
+		return err
+	}
+	return q.RestartTrackingTransaction(ctx, db.RestartTrackingTransactionParams(params))
+}
+
+// ResumeTracking restarts tracking of every transaction that was in flight
+// when the scanner stopped, adopting signed, broadcast or mined transactions
+// whose scan event was never handled. A signed transaction may have reached
+// the network even though the wallet could not tell; tracking its hash
+// settles it either way.
+func (server *Server) ResumeTracking(ctx context.Context) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	untracked, err := server.q.ListUntrackedInFlightTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tx := range untracked {
+		err := startTracking(ctx, server.q, transactionTrackedEvent(tx, time.Now().UTC()))
+		if err != nil {
+			return err
+		}
+	}
+
+	active, err := server.q.ListActiveTrackedTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, row := range active {
+		tracked := trackedEventFromRow(row)
+		pool, err := server.clients.Pool(tracked.ChainID)
+		if err != nil {
+			logger.Error("Cannot resume tracking transaction",
+				slog.String("tx_hash", tracked.TransactionHash),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		go server.trackTransaction(tracked, pool)
+	}
+
+	logger.Info("Resumed tracking transactions",
+		slog.Int("adopted", len(untracked)),
+		slog.Int("tracked", len(active)),
+	)
+	return nil
+}
+
+// TransactionResultEvent is a change in a tracked transaction's status. It is
+// published as the event matching Status; see resultEnvelope.
+type TransactionResultEvent struct {
+	TransactionID   int64
+	TransactionHash string
+	ChainID         string
+	FromAddress     string
+	CorrelationID   string
+	Status          string
+	BlockNumber     uint64
+	BlockHash       string
+	Confirmations   uint64
+	Balance         string
+	ReplacedBy      string
+}
+
+// failureReasons maps the result statuses that end a transaction
+// unsuccessfully to the reason reported in its event.
+var failureReasons = map[string]string{
+	ResultStatusFailed:   event.FailureExecutionReverted,
+	ResultStatusDropped:  event.FailureDropped,
+	ResultStatusReplaced: event.FailureReplaced,
+}
+
+// resultEnvelope wraps result in the event for its status.
+func resultEnvelope(result *TransactionResultEvent) (event.Envelope, error) {
+	if reason, ok := failureReasons[result.Status]; ok {
+		return event.New(event.TypeTransactionFailed, result.ChainID, result.CorrelationID, &event.TransactionFailed{
+			TransactionID:   result.TransactionID,
+			TransactionHash: result.TransactionHash,
+			FromAddress:     result.FromAddress,
+			Reason:          reason,
+			BlockNumber:     result.BlockNumber,
+			BlockHash:       result.BlockHash,
+			ReplacedBy:      result.ReplacedBy,
+			Balance:         result.Balance,
+		})
+	}
+
+	var eventType string
+	switch result.Status {
+	case ResultStatusMined:
+		eventType = event.TypeTransactionMined
+	case ResultStatusConfirmed:
+		eventType = event.TypeTransactionConfirmed
+	case ResultStatusReverted:
+		eventType = event.TypeTransactionReorged
+	default:
+		return event.Envelope{}, fmt.Errorf("%w: result status %q", event.ErrUnknownType, result.Status)
+	}
+	return event.New(eventType, result.ChainID, result.CorrelationID, &event.TransactionConfirmed{
+		TransactionID:   result.TransactionID,
+		TransactionHash: result.TransactionHash,
+		FromAddress:     result.FromAddress,
+		BlockNumber:     result.BlockNumber,
+		BlockHash:       result.BlockHash,
+		Confirmations:   result.Confirmations,
+		Balance:         result.Balance,
+	})
+}
+
+// trackTransaction polls the chain until the transaction reaches the configured
+// number of confirmations, fails on-chain, or is dropped, writing a result
+// event to the outbox for each state change together with the tracking
+// state. A transaction already tracked by this process is left alone.
+func (server *Server) trackTransaction(tracked TransactionEvent, pool *chain.Pool) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("tx_hash", tracked.TransactionHash),
+		slog.String("chain_id", tracked.ChainID),
+	)
+
+	if _, running := server.tracking.LoadOrStore(tracked.TransactionID, struct{}{}); running {
+		return
+	}
+	defer server.tracking.Delete(tracked.TransactionID)
+
+	tracker := &txTracker{
+		server:        server,
+		pool:          pool,
+		event:         tracked,
+		hash:          common.HexToHash(tracked.TransactionHash),
+		from:          common.HexToAddress(tracked.FromAddress),
+		started:       tracked.StartedAt,
+		minedReported: tracked.MinedBlockHash != "",
+		minedBlock:    common.HexToHash(tracked.MinedBlockHash),
+	}
+
+	ticker := time.NewTicker(server.config.PollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		saved := *tracker
+		result, done := tracker.poll(ctx)
+		if result != nil {
+			err := server.store.ExecTx(ctx, func(q *db.Queries) error {
+				err := enqueueResult(ctx, q, result)
+				if err != nil {
+					return err
+				}
+				return tracker.save(ctx, q, done)
+			})
+			if err != nil {
+				// Forget what this poll saw so the change is reported again.
+				logger.Error("Failed to record transaction status",
+					slog.String("status", result.Status),
+					slog.Any("error", err),
+				)
+				*tracker = saved
+				cancel()
+				continue
+			}
+			logger.Info("Transaction status changed", slog.String("status", result.Status))
+		}
+		cancel()
+
+		if done {
+			return
+		}
+	}
+}
+
+// txTracker holds the polling state for a single submitted transaction.
+type txTracker struct {
+	server        *Server
+	pool          *chain.Pool
+	client        *ethclient.Client
+	event         TransactionEvent
+	hash          common.Hash
+	from          common.Address
+	started       time.Time
+	minedReported bool
+	minedBlock    common.Hash
+}
+
+// save persists the tracking state, marking the transaction finished if done.
+func (t *txTracker) save(ctx context.Context, q *db.Queries, done bool) error {
+	if done {
+		return q.FinishTrackedTransaction(ctx, db.FinishTrackedTransactionParams{
+			TransactionID: t.event.TransactionID,
+			FinishedAt:    pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		})
+	}
+
+	var minedBlock pgtype.Text
+	if t.minedReported {
+		minedBlock = pgtype.Text{String: t.minedBlock.Hex(), Valid: true}
+	}
+	return q.SetTrackedTransactionMinedBlock(ctx, db.SetTrackedTransactionMinedBlockParams{
+		TransactionID:  t.event.TransactionID,
+		MinedBlockHash: minedBlock,
+	})
+}
+
+// poll checks the transaction once. It returns a result event when the status
+// changed, and done once the transaction reached a final state.
+func (t *txTracker) poll(ctx context.Context) (*TransactionResultEvent, bool) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	tracked := t.event
+	// Pick up the pool's preferred endpoint in case it rotated since last poll.
+	t.client = t.pool.Client()
+
+	result := &TransactionResultEvent{
+		TransactionID:   tracked.TransactionID,
+		TransactionHash: tracked.TransactionHash,
+		ChainID:         tracked.ChainID,
+		FromAddress:     tracked.FromAddress,
+		CorrelationID:   tracked.CorrelationID,
+	}
+
+	receipt, err := t.client.TransactionReceipt(ctx, t.hash)
+	if errors.Is(err, ethereum.NotFound) {
+		if t.minedReported {
+			// The block holding the transaction was reorganized away.
+			t.minedReported = false
+			t.minedBlock = common.Hash{}
+			result.Status = ResultStatusReverted
+			return result, false
+		}
+		if t.isDropped(ctx) {
+			if winner := t.minedReplacement(ctx); winner != "" {
+				result.Status = ResultStatusReplaced
+				result.ReplacedBy = winner
+				return result, true
+			}
+			result.Status = ResultStatusDropped
+			return result, true
+		}
+		return nil, false
+	}
+	if err != nil {
+		logger.Error("Failed to get transaction receipt",
+			slog.String("tx_hash", tracked.TransactionHash),
+			slog.Any("error", err),
+		)
+		return nil, false
+	}
+
+	result.BlockNumber = receipt.BlockNumber.Uint64()
+	result.BlockHash = receipt.BlockHash.Hex()
+	if receipt.Status == types.ReceiptStatusFailed {
+		result.Status = ResultStatusFailed
+		result.Balance = balanceOf(ctx, t.client, t.from)
+		return result, true
+	}
+
+	head, err := t.client.BlockNumber(ctx)
+	if err != nil {
+		logger.Error("Failed to get block number",
+			slog.String("chain_id", tracked.ChainID),
+			slog.Any("error", err),
+		)
+		return nil, false
+	}
+
+	if head >= result.BlockNumber {
+		result.Confirmations = head - result.BlockNumber + 1
+	}
+
+	if result.Confirmations >= t.server.config.Confirmations {
+		result.Status = ResultStatusConfirmed
+		result.Balance = balanceOf(ctx, t.client, t.from)
+		return result, true
+	}
+
+	// Report mined again if a reorg moved the transaction to another block.
+	if !t.minedReported || t.minedBlock != receipt.BlockHash {
+		t.minedReported = true
+		t.minedBlock = receipt.BlockHash
+		result.Status = ResultStatusMined
+		return result, false
+	}
+	return nil, false
+}
+
+// isDropped reports whether a transaction with no receipt will never be mined:
+// either another transaction has consumed its nonce, or the node has forgotten
+// it after the drop timeout.
+func (t *txTracker) isDropped(ctx context.Context) bool {
+	nonce, err := t.client.NonceAt(ctx, t.from, nil)
+	if err == nil && nonce > t.event.Nonce {
+		// The nonce may have been consumed by this very transaction between
+		// the receipt lookup and now.
+		_, err = t.client.TransactionReceipt(ctx, t.hash)
+		return errors.Is(err, ethereum.NotFound)
+	}
+
+	if time.Since(t.started) < t.server.config.DropTimeout {
+		return false
+	}
+
+	_, _, err = t.client.TransactionByHash(ctx, t.hash)
+	return errors.Is(err, ethereum.NotFound)
+}
+
+// minedReplacement returns the hash of another transaction from the same
+// sender with the same nonce, i.e. a speed-up or cancel of this one or the
+// transaction it replaced, that made it on-chain instead.
+func (t *txTracker) minedReplacement(ctx context.Context) string {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	chainID, err := strconv.ParseInt(t.event.ChainID, 10, 32)
+	if err != nil {
+		return ""
+	}
+
+	family, err := t.server.q.ListTransactionsByNonce(ctx, db.ListTransactionsByNonceParams{
+		ChainID:     int32(chainID),
+		FromAddress: t.event.FromAddress,
+		Nonce:       pgtype.Int8{Int64: int64(t.event.Nonce), Valid: true},
+	})
+	if err != nil {
+		logger.Error("Failed to list replacement transactions",
+			slog.String("tx_hash", t.event.TransactionHash),
+			slog.Any("error", err),
+		)
+		return ""
+	}
+
+	for _, member := range family {
+		if !member.Hash.Valid || member.Hash.String == t.event.TransactionHash {
+			continue
+		}
+		_, err := t.client.TransactionReceipt(ctx, common.HexToHash(member.Hash.String))
+		if err == nil {
+			return member.Hash.String
+		}
+	}
+	return ""
+}
+
+func balanceOf(ctx context.Context, client *ethclient.Client, address common.Address) string {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	balance, err := client.BalanceAt(ctx, address, nil)
+	if err != nil {
+		logger.Error("Failed to get balance",
+			slog.String("address", address.Hex()),
+			slog.Any("error", err),
+		)
+		return ""
+	}
+	return balance.String()
+}
+
+// enqueueResult writes result to the outbox for delivery on the result queue.
+func enqueueResult(ctx context.Context, q *db.Queries, result *TransactionResultEvent) error {
+	envelope, err := resultEnvelope(result)
+	if err != nil {
+		return err
+	}
+	return store.Enqueue(ctx, q, outboxProducer, event.ResultQueue, envelope)
+}
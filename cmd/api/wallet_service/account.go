@@ -2,314 +2,547 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"math/big"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type CreateAccountRequest struct {
-	UserID  int64 `json:"user_id"`
-	ChainID int32 `json:"chain_id"`
+	ChainID  int32  `json:"chain_id"`
+	Password string `json:"password"`
 }
 
 type CreateAccountResponse struct {
-	Address    string `json:"address"`
-	PublicKey  string `json:"public_key"`
-	PrivateKey string `json:"private_key"`
-	Messsage   string `json:"message"`
+	Address        string `json:"address"`
+	PublicKey      string `json:"public_key"`
+	DerivationPath string `json:"derivation_path"`
+	Mnemonic       string `json:"mnemonic,omitempty"`
+	Messsage       string `json:"message"`
 }
 
+// CreateTransactionRequest names the destination either by to_address or by
+// the id of a saved contact. Amount is a decimal number, or string, in Unit.
 type CreateTransactionRequest struct {
-	AccountId  int64  `json:"account_id"`
-	ToAddress  string `json:"to_address"`
-	Amount     int64  `json:"amount"`
-	ChainId    string `json:"chain_id"`
-	PrivateKey string `json:"private_key"`
+	AccountId            int64       `json:"account_id"`
+	ToAddress            string      `json:"to_address,omitempty"`
+	ContactID            *int64      `json:"contact_id,omitempty"`
+	Amount               json.Number `json:"amount"`
+	Unit                 string      `json:"unit,omitempty"`
+	ChainId              string      `json:"chain_id"`
+	Password             string      `json:"password"`
+	MaxFeePerGas         *big.Int    `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int    `json:"max_priority_fee_per_gas,omitempty"`
+}
+
+type CreateTokenTransactionRequest struct {
+	AccountId            int64    `json:"account_id"`
+	ChainId              string   `json:"chain_id"`
+	Token                string   `json:"token"`
+	ToAddress            string   `json:"to_address,omitempty"`
+	ContactID            *int64   `json:"contact_id,omitempty"`
+	Amount               string   `json:"amount"`
+	Password             string   `json:"password"`
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
 }
 
 type CreateTransactionResponse struct {
 	Messsage        string `json:"message"`
+	TransactionID   int64  `json:"transaction_id"`
 	TransactionHash string `json:"transaction_hash"`
 	ToAddress       string `json:"to_address"`
 	Status          string `json:"status"`
 }
 
-type TransactionEvent struct {
-	TransactionHash string `json:"transaction_hash"`
-	FromAddress     string `json:"from_address"`
-	ToAddress       string `json:"to_address"`
-	Amount          int64  `json:"amount"`
+// newTransactionEvent announces tx to the scanner. For token transfers the
+// on-chain transaction goes to the token contract and carries no value.
+func newTransactionEvent(tx db.Transaction) (event.Envelope, error) {
+	payload := &event.TransactionSubmitted{
+		TransactionID:   tx.ID,
+		TransactionHash: tx.Hash.String,
+		FromAddress:     tx.FromAddress,
+		ToAddress:       tx.ToAddress,
+		Value:           "0",
+		Nonce:           uint64(tx.Nonce.Int64),
+	}
+	if tx.TokenAddress.Valid {
+		payload.ToAddress = tx.TokenAddress.String
+	} else {
+		payload.Value = store.NumericToBig(tx.Value).String()
+	}
+	return event.New(
+		event.TypeTransactionSubmitted,
+		strconv.Itoa(int(tx.ChainID)),
+		event.TransactionCorrelationID(tx.ID),
+		payload,
+	)
 }
 
-func createAddress() (string, string, string, error) {
+func createAddress(mnemonic string, index uint32) (string, string, error) {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	privateKey, err := crypto.GenerateKey()
+	privateKey, err := deriveAccountKey(mnemonic, index)
 	if err != nil {
-		logger.Error("Failed to generate private key",
+		logger.Error("Failed to derive private key",
+			slog.Uint64("index", uint64(index)),
 			slog.Any("error", err),
 		)
-		return "", "", "", err
+		return "", "", err
 	}
 
-	privateKeyBytes := crypto.FromECDSA(privateKey)
-	privateKeyStr := hexutil.Encode(privateKeyBytes)[2:]
-	logger.Warn("Private key generated", slog.String("private_key", privateKeyStr))
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		logger.Error("Failed to assert type: publicKey is not of type *ecdsa.PublicKey")
-		return "", "", "", err
-	}
-
-	publicKeyBytes := crypto.FromECDSAPub(publicKeyECDSA)
+	publicKeyBytes := crypto.FromECDSAPub(&privateKey.PublicKey)
 	pubKeyStr := hexutil.Encode(publicKeyBytes)[4:]
-	logger.Warn("Public key generated", slog.String("public_key", pubKeyStr))
+	logger.Info("Public key generated", slog.String("public_key", pubKeyStr))
 
-	address := crypto.PubkeyToAddress(*publicKeyECDSA).Hex()
-	logger.Warn("Address generated", slog.String("address", address))
+	address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	logger.Info("Address generated", slog.String("address", address))
 
-	return address, pubKeyStr, privateKeyStr, nil
+	return address, pubKeyStr, nil
 }
 
 func (server *Server) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed!", http.StatusMethodNotAllowed)
+		respond.MethodNotAllowed(w)
 		return
 	}
 
 	newAccount := &CreateAccountRequest{}
 	err := json.NewDecoder(r.Body).Decode(newAccount)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respond.InvalidBody(w, err)
 		return
 	}
 
-	address, pubKey, privateKey, err := createAddress()
+	userID := callerID(r.Context())
+	err = server.verifyWalletPassword(r.Context(), userID, newAccount.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	_, err = server.q.CreateAccount(r.Context(), db.CreateAccountParams{
-		UserID:  newAccount.UserID,
-		ChainID: newAccount.ChainID,
-		Address: address,
+	var address, pubKey, mnemonic string
+	var index int32
+	var seedCreated bool
+	err = server.store.ExecTx(r.Context(), func(q *db.Queries) error {
+		mnemonic, seedCreated, err = ensureWalletSeed(r.Context(), q, userID, newAccount.Password)
+		if err != nil {
+			return err
+		}
+
+		index, err = q.ReserveDerivationIndex(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+
+		address, pubKey, err = createAddress(mnemonic, uint32(index))
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAccount(r.Context(), db.CreateAccountParams{
+			UserID:          userID,
+			ChainID:         newAccount.ChainID,
+			Address:         address,
+			DerivationIndex: pgtype.Int4{Int32: index, Valid: true},
+		})
+		return err
 	})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
 	response := &CreateAccountResponse{
-		Messsage:   "Account created successfully!",
-		Address:    address,
-		PublicKey:  pubKey,
-		PrivateKey: privateKey,
+		Messsage:       "Account created successfully!",
+		Address:        address,
+		PublicKey:      pubKey,
+		DerivationPath: accountDerivationPath(uint32(index)).String(),
+	}
+	// Users created before HD support get their seed here; this is the only
+	// time the mnemonic is returned, so surface it for backup.
+	if seedCreated {
+		response.Mnemonic = mnemonic
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(*response)
+	respond.JSON(w, http.StatusCreated, *response)
 }
 
-func makeTransaction(pk string, fromHexAddress string, toHexAddress string, value *big.Int, client *ethclient.Client) (string, error) {
+// makeTransaction signs and broadcasts the transfer recorded in tx using the
+// key held in the keystore, so callers never handle the private key. The
+// record is advanced through signed and broadcast as each step succeeds.
+// Post-London chains get an EIP-1559 transaction; others a legacy one.
+func (server *Server) makeTransaction(ctx context.Context, tx db.Transaction, password string, feeOpts FeeOptions, client *ethclient.Client) (db.Transaction, error) {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
-	transactionHash := ""
-	err := error(nil)
-
-	privateKey, err := crypto.HexToECDSA(pk)
+	privateKey, err := server.loadAccountKey(ctx, tx.AccountID, password)
 	if err != nil {
-		logger.Error("Error converting hex to ECDSA", slog.Any("error", err))
-		return transactionHash, err
+		return tx, err
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		logger.Error("Cannot assert type: publicKey is not of type *ecdsa.PublicKey")
-		return transactionHash, err
-	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-	if fromAddress.Hex() != fromHexAddress {
-		logger.Error(
-			"Address mismatch",
-			slog.String("from_address", fromAddress.Hex()),
-			slog.String("from_hex_address", fromHexAddress),
-		)
-		return transactionHash, err
-	}
-
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	callTo, callValue, data, err := transferCall(common.HexToAddress(tx.ToAddress), store.NumericToBig(tx.Value), tx.TokenAddress.String)
 	if err != nil {
-		logger.Error("Error in getting nonce", slog.Any("error", err))
-		return transactionHash, err
+		logger.Error("Error in encoding transfer", slog.Any("error", err))
+		return tx, err
 	}
 
 	gasLimit := uint64(21000)
-	gasPrice, err := client.SuggestGasPrice(context.Background())
+	if data != nil {
+		gasLimit, err = client.EstimateGas(ctx, ethereum.CallMsg{
+			From:  fromAddress,
+			To:    &callTo,
+			Value: callValue,
+			Data:  data,
+		})
+		if err != nil {
+			logger.Error("Error in estimating gas", slog.Any("error", err))
+			return tx, err
+		}
+	}
+
+	fees, err := suggestFees(ctx, client, feeOpts)
 	if err != nil {
-		logger.Error("Error in getting gas price", slog.Any("error", err))
-		return transactionHash, err
+		logger.Error("Error in getting gas fees", slog.Any("error", err))
+		return tx, err
 	}
 
-	toAddress := common.HexToAddress(toHexAddress)
-	var data []byte
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, gasPrice, data)
+	if tx.ReplacesID.Valid {
+		original, err := server.q.GetTransactionById(ctx, tx.ReplacesID.Int64)
+		if err != nil {
+			return tx, err
+		}
+		fees, err = applyReplacementFloor(fees, original, feeOpts)
+		if err != nil {
+			return tx, err
+		}
+	}
 
-	chainID, err := client.NetworkID(context.Background())
+	chainID, err := client.ChainID(ctx)
 	if err != nil {
-		logger.Error("Error in getting network ID", slog.Any("error", err))
-		return transactionHash, err
+		logger.Error("Error in getting chain ID", slog.Any("error", err))
+		return tx, err
+	}
+
+	// Replacements are created with the nonce of the transaction they replace.
+	nonce := uint64(tx.Nonce.Int64)
+	reserved := !tx.Nonce.Valid
+	if reserved {
+		nonce, err = server.reserveNonce(ctx, client, tx.ChainID, tx.FromAddress)
+		if err != nil {
+			logger.Error("Error in reserving nonce", slog.Any("error", err))
+			return tx, err
+		}
+	}
+	release := func() {
+		if reserved {
+			server.releaseNonce(ctx, tx.ChainID, tx.FromAddress, nonce)
+		}
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	rawTx, signer := newUnsignedTx(chainID, nonce, callTo, callValue, gasLimit, data, fees)
+
+	signedTx, err := types.SignTx(rawTx, signer, privateKey)
 	if err != nil {
 		logger.Error("Error in signing transaction", slog.Any("error", err))
-		return transactionHash, err
+		release()
+		return tx, err
 	}
 
-	err = client.SendTransaction(context.Background(), signedTx)
+	// The scanner is told to track the hash in the same database transaction
+	// that records the signature, before the send. Whatever happens to the
+	// send from here on, the transaction is settled by what the chain shows.
+	var signed db.Transaction
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		signed, err = q.MarkTransactionSigned(ctx, db.MarkTransactionSignedParams{
+			ID:                   tx.ID,
+			Nonce:                pgtype.Int8{Int64: int64(nonce), Valid: true},
+			GasLimit:             pgtype.Int8{Int64: int64(gasLimit), Valid: true},
+			GasPrice:             store.BigToNumeric(fees.GasPrice),
+			Hash:                 pgtype.Text{String: signedTx.Hash().Hex(), Valid: true},
+			TxType:               int32(fees.Type),
+			MaxFeePerGas:         store.BigToNumeric(fees.MaxFeePerGas),
+			MaxPriorityFeePerGas: store.BigToNumeric(fees.MaxPriorityFeePerGas),
+		})
+		if err != nil {
+			return err
+		}
+		envelope, err := newTransactionEvent(signed)
+		if err != nil {
+			return err
+		}
+		return store.Enqueue(ctx, q, outboxProducer, event.ScanQueue, envelope)
+	})
+	if err != nil {
+		logger.Error("Error in recording signed transaction", slog.Any("error", err))
+		release()
+		return tx, err
+	}
+	tx = signed
+
+	err = client.SendTransaction(ctx, signedTx)
+	if err != nil && alreadyKnown(err) {
+		// An earlier attempt reached the node after all, e.g. one that timed
+		// out on the way back; the transaction is in its pool.
+		logger.Warn("Transaction already known to the node", slog.String("tx_hash", tx.Hash.String))
+		err = nil
+	}
 	if err != nil {
 		logger.Error("Error in sending transaction", slog.Any("error", err))
-		return transactionHash, err
+		switch {
+		case nonceTooLow(err):
+			// Another transaction took the nonce, so our counter is behind the
+			// chain; catch up now rather than on the next restart.
+			if syncErr := server.syncNonce(ctx, client, tx.ChainID, tx.FromAddress, false); syncErr != nil {
+				logger.Error("Error in resyncing nonce", slog.Any("error", syncErr))
+			}
+		case sendRejected(err):
+			release()
+		}
+		// Otherwise the send failed in transit and the transaction may still
+		// have propagated, so its nonce stays reserved until reconcileNonces
+		// finds the chain never used it.
+		return tx, err
+	}
+
+	// The transaction is on the network and already tracked, so a failure to
+	// record the broadcast is not a failed send. The record stays signed, as
+	// the response says, until the scanner reports the transaction mined.
+	broadcast, err := transitionTransaction(context.WithoutCancel(ctx), server.q, tx, TxStatusBroadcast, nil)
+	if err != nil {
+		logger.Error("Error in recording broadcast transaction", slog.Any("error", err))
+		return tx, nil
 	}
+	tx = broadcast
 
-	txHash := signedTx.Hash().Hex()
-	logger.Info("Transaction hash", slog.String("tx_hash", txHash))
-	return txHash, err
+	logger.Info("Transaction hash", slog.String("tx_hash", tx.Hash.String))
+	return tx, nil
 }
 
-func (server *Server) emitTransactionEvent(queueName string, event *TransactionEvent) {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	ch, err := server.queueConn.Channel()
+func (server *Server) CreateTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	newTransaction := &CreateTransactionRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(newTransaction)
 	if err != nil {
-		logger.Error("Failed to open a channel",
-			slog.Any("error", err),
-		)
+		respond.InvalidBody(w, err)
 		return
 	}
-	defer ch.Close()
 
-	q, err := ch.QueueDeclare(
-		queueName, // name
-		false,    // durable
-		false,    // delete when unused
-		false,    // exclusive
-		false,    // no-wait
-		nil,      // arguments
-	)
+	amount, invalid := server.validateCreateTransaction(newTransaction)
+	if invalid != nil {
+		writeError(w, invalid)
+		return
+	}
+
+	client, err := server.clients.Client(newTransaction.ChainId)
 	if err != nil {
-		logger.Error("Failed to declare a queue",
-			slog.Any("error", err),
-		)
+		writeError(w, err)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	account, ok := server.authorizeAccount(w, r, newTransaction.AccountId)
+	if !ok {
+		return
+	}
+	if invalid := validateAccountChain(newTransaction.ChainId, account.ChainID); invalid != nil {
+		writeError(w, invalid)
+		return
+	}
 
-	body, err := json.Marshal(event)
+	toAddress, err := server.resolveDestination(r.Context(), account, newTransaction.ToAddress, newTransaction.ContactID)
 	if err != nil {
-		logger.Error("Failed to marshal event",
-			slog.Any("error", err),
-		)
+		writeError(w, err)
 		return
 	}
 
-	err = ch.PublishWithContext(
-		ctx,
-		"",     // exchange
-		q.Name, // routing key
-		false,  // mandatory
-		false,  // immediate
-		amqp.Publishing{
-            ContentType: "application/json",
-			Body:        []byte(body),
-		})
+	feeOpts := FeeOptions{
+		MaxFeePerGas:         newTransaction.MaxFeePerGas,
+		MaxPriorityFeePerGas: newTransaction.MaxPriorityFeePerGas,
+	}
+	record, err := server.submitTransaction(r.Context(), db.CreateTransactionParams{
+		AccountID:   account.ID,
+		ChainID:     account.ChainID,
+		FromAddress: account.Address,
+		ToAddress:   toAddress,
+		Value:       store.BigToNumeric(amount),
+	}, newTransaction.Password, feeOpts, client)
 	if err != nil {
-		logger.Error("Failed to publish a message",
-			slog.Any("error", err),
-		)
+		writeError(w, err)
 		return
 	}
-	logger.Info("Event published successfully")
+
+	writeSubmittedTransaction(w, record)
 }
 
-func (server *Server) CreateTransaction(w http.ResponseWriter, r *http.Request) {
+// CreateTokenTransaction sends an ERC-20 transfer. The token is given by
+// contract address or by a symbol from the chain's token registry, and the
+// amount in whole tokens, e.g. "12.5".
+func (server *Server) CreateTokenTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed!", http.StatusMethodNotAllowed)
+		respond.MethodNotAllowed(w)
 		return
 	}
 
-	newTransaction := &CreateTransactionRequest{}
+	newTransaction := &CreateTokenTransactionRequest{}
 
 	err := json.NewDecoder(r.Body).Decode(newTransaction)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respond.InvalidBody(w, err)
 		return
 	}
 
-	// Get the chain URL
-	var chainURL string
-	for _, chainItem := range server.ethConfig.ChainItemList {
-		if chainItem.ChainID == newTransaction.ChainId {
-			chainURL = chainItem.RPCURL
-		}
+	if invalid := server.validateCreateTokenTransaction(newTransaction); invalid != nil {
+		writeError(w, invalid)
+		return
+	}
+
+	client, err := server.clients.Client(newTransaction.ChainId)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	account, ok := server.authorizeAccount(w, r, newTransaction.AccountId)
+	if !ok {
+		return
+	}
+	if invalid := validateAccountChain(newTransaction.ChainId, account.ChainID); invalid != nil {
+		writeError(w, invalid)
+		return
 	}
 
-	client, err := ethclient.Dial(chainURL)
+	toAddress, err := server.resolveDestination(r.Context(), account, newTransaction.ToAddress, newTransaction.ContactID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	fromHexAddress, err := server.q.GetAccountAddressById(r.Context(), newTransaction.AccountId)
+	token, err := server.resolveToken(r.Context(), client, newTransaction.ChainId, newTransaction.Token)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, errUnknownToken) {
+			invalid := &ValidationError{}
+			invalid.Add("token", err.Error())
+			writeError(w, invalid)
+			return
+		}
+		writeError(w, err)
 		return
 	}
 
-	transactionHash, err := makeTransaction(newTransaction.PrivateKey, fromHexAddress, newTransaction.ToAddress, big.NewInt(newTransaction.Amount), client)
+	amount, err := parseTokenAmount(newTransaction.Amount, token.Decimals)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		invalid := &ValidationError{}
+		invalid.Add("amount", err.Error())
+		writeError(w, invalid)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	response := &CreateTransactionResponse{
-		Messsage:        "Transaction created!",
-		TransactionHash: transactionHash,
-		ToAddress:       newTransaction.ToAddress,
-		Status:          "pending_confirmation",
+	feeOpts := FeeOptions{
+		MaxFeePerGas:         newTransaction.MaxFeePerGas,
+		MaxPriorityFeePerGas: newTransaction.MaxPriorityFeePerGas,
 	}
+	record, err := server.submitTransaction(r.Context(), db.CreateTransactionParams{
+		AccountID:    account.ID,
+		ChainID:      account.ChainID,
+		FromAddress:  account.Address,
+		ToAddress:    toAddress,
+		Value:        store.BigToNumeric(amount),
+		TokenAddress: pgtype.Text{String: token.Address, Valid: true},
+	}, newTransaction.Password, feeOpts, client)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSubmittedTransaction(w, record)
+}
+
+// submitTransaction checks the caller's spending policies, records a new
+// transaction and signs and broadcasts it. A transaction that could not be
+// sent is marked failed. One that needs approval is returned pending instead;
+// its owner executes it once approved.
+func (server *Server) submitTransaction(ctx context.Context, params db.CreateTransactionParams, password string, feeOpts FeeOptions, client *ethclient.Client) (db.Transaction, error) {
+	userID := callerID(ctx)
+
+	var record db.Transaction
+	err := server.store.ExecTx(ctx, func(q *db.Queries) error {
+		// Serialize the user's sends until the record exists, so concurrent
+		// requests cannot each fit under a limit that together they exceed.
+		err := q.LockUserSpending(ctx, db.LockUserSpendingParams{
+			Namespace: spendingLockNamespace,
+			UserID:    userID,
+		})
+		if err != nil {
+			return err
+		}
 
-	event := &TransactionEvent{
-		TransactionHash: transactionHash,
-		FromAddress:     fromHexAddress,
-		ToAddress:       newTransaction.ToAddress,
-		Amount:          newTransaction.Amount,
+		err = checkSpendingPolicies(ctx, q, userID, params, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		record, err = q.CreateTransaction(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		record, err = requestApproval(ctx, q, record, time.Now().UTC())
+		return err
+	})
+	if err != nil || record.Status == TxStatusPendingApproval {
+		return record, err
+	}
+
+	record, err = server.makeTransaction(ctx, record, password, feeOpts, client)
+	if err != nil {
+		if neverSent(record, err) {
+			server.failTransaction(ctx, record, err)
+		}
+		return record, err
 	}
+	return record, nil
+}
 
-	server.emitTransactionEvent("scan_queue", event)
+// writeSubmittedTransaction responds to a create request with record, which
+// is either on the network (201) or waiting for approval (202).
+func writeSubmittedTransaction(w http.ResponseWriter, record db.Transaction) {
+	response := &CreateTransactionResponse{
+		Messsage:        "Transaction created!",
+		TransactionID:   record.ID,
+		TransactionHash: record.Hash.String,
+		ToAddress:       record.ToAddress,
+		Status:          record.Status,
+	}
+	status := http.StatusCreated
+	if record.Status == TxStatusPendingApproval {
+		response.Messsage = "Transaction is awaiting approval!"
+		status = http.StatusAccepted
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(*response)
+	respond.JSON(w, status, *response)
 }
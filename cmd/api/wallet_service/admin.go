@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/jackc/pgx/v5"
+)
+
+// requireAdmin only lets requests through that carry the configured admin
+// token as a bearer token. Without a configured token admin routes are off.
+func (server *Server) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		expected := server.config.AdminToken
+		if expected == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			respond.WriteError(w, http.StatusForbidden, respond.CodeForbidden, "Forbidden")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminDeleteApprovalRule deletes a rule without the approvers' consent, for
+// when they can no longer reach a quorum.
+func (server *Server) AdminDeleteApprovalRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ApprovalRuleRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	rule, err := server.q.GetApprovalRuleById(r.Context(), request.RuleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRuleNotFound, "Approval rule not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	err = server.q.DeleteApprovalRule(r.Context(), rule.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
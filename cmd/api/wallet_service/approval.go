@@ -0,0 +1,821 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Approval decisions, stored in transaction_approvals.decision.
+const (
+	ApprovalDecisionApprove = "approve"
+	ApprovalDecisionReject  = "reject"
+)
+
+const (
+	defaultApprovalTTL    = 24 * time.Hour
+	approvalSweepInterval = time.Minute
+)
+
+var (
+	errNotApprover       = errors.New("caller is not an approver of this transaction")
+	errAlreadyDecided    = errors.New("caller has already decided on this transaction")
+	errApprovalExpired   = errors.New("approval request has expired")
+	errNotPendingApprove = errors.New("transaction is not awaiting approval")
+	errNoRuleDeletion    = errors.New("no deletion was requested for this rule")
+	errRejectedByVote    = errors.New("rejected by approvers")
+)
+
+type CreateApprovalRuleRequest struct {
+	AccountID         int64    `json:"account_id"`
+	TokenAddress      string   `json:"token_address,omitempty"`
+	Threshold         *big.Int `json:"threshold"`
+	RequiredApprovals int32    `json:"required_approvals"`
+	ApproverIDs       []int64  `json:"approver_ids"`
+	TTLSeconds        int32    `json:"ttl_seconds,omitempty"`
+}
+
+type ApprovalRuleRequest struct {
+	RuleID int64 `json:"rule_id"`
+}
+
+type ApprovalRuleResponse struct {
+	ID                int64     `json:"id"`
+	AccountID         int64     `json:"account_id"`
+	TokenAddress      string    `json:"token_address,omitempty"`
+	Threshold         string    `json:"threshold"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ApproverIDs       []int64   `json:"approver_ids"`
+	TTLSeconds        int32     `json:"ttl_seconds"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ApprovalRuleDeletionResponse struct {
+	RuleID            int64     `json:"rule_id"`
+	RequestedBy       int64     `json:"requested_by"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ApproverIDs       []int64   `json:"approver_ids"`
+	ApprovedBy        []int64   `json:"approved_by"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	Deleted           bool      `json:"deleted"`
+}
+
+type ListApprovalRulesResponse struct {
+	Rules []ApprovalRuleResponse `json:"rules"`
+}
+
+type ApprovalDecisionRequest struct {
+	TransactionID int64  `json:"transaction_id"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+type ExecuteTransactionRequest struct {
+	TransactionID        int64    `json:"transaction_id"`
+	Password             string   `json:"password"`
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+}
+
+type ApprovalResponse struct {
+	UserID    int64     `json:"user_id"`
+	Decision  string    `json:"decision"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApprovalRequestResponse struct {
+	Transaction       TransactionResponse `json:"transaction"`
+	RequiredApprovals int32               `json:"required_approvals"`
+	ApproverIDs       []int64             `json:"approver_ids"`
+	ExpiresAt         time.Time           `json:"expires_at"`
+	Approvals         []ApprovalResponse  `json:"approvals"`
+}
+
+type ListPendingApprovalsResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+}
+
+func newApprovalRuleResponse(rule db.ApprovalRule) ApprovalRuleResponse {
+	return ApprovalRuleResponse{
+		ID:                rule.ID,
+		AccountID:         rule.AccountID,
+		TokenAddress:      rule.TokenAddress.String,
+		Threshold:         store.NumericString(rule.Threshold),
+		RequiredApprovals: rule.RequiredApprovals,
+		ApproverIDs:       rule.ApproverIds,
+		TTLSeconds:        rule.TtlSeconds,
+		CreatedAt:         rule.CreatedAt.Time,
+	}
+}
+
+func newApprovalRuleDeletionResponse(rule db.ApprovalRule, deletion db.ApprovalRuleDeletion, deleted bool) ApprovalRuleDeletionResponse {
+	return ApprovalRuleDeletionResponse{
+		RuleID:            rule.ID,
+		RequestedBy:       deletion.RequestedBy,
+		RequiredApprovals: rule.RequiredApprovals,
+		ApproverIDs:       rule.ApproverIds,
+		ApprovedBy:        deletion.ApprovedBy,
+		ExpiresAt:         deletion.ExpiresAt.Time,
+		Deleted:           deleted,
+	}
+}
+
+func newApprovalRequestResponse(tx db.Transaction, request db.ApprovalRequest, approvals []db.TransactionApproval) ApprovalRequestResponse {
+	response := ApprovalRequestResponse{
+		Transaction:       newTransactionResponse(tx),
+		RequiredApprovals: request.RequiredApprovals,
+		ApproverIDs:       request.ApproverIds,
+		ExpiresAt:         request.ExpiresAt.Time,
+		Approvals:         make([]ApprovalResponse, 0, len(approvals)),
+	}
+	for _, approval := range approvals {
+		response.Approvals = append(response.Approvals, ApprovalResponse{
+			UserID:    approval.UserID,
+			Decision:  approval.Decision,
+			Comment:   approval.Comment.String,
+			CreatedAt: approval.CreatedAt.Time,
+		})
+	}
+	return response
+}
+
+func isApprover(approverIDs []int64, userID int64) bool {
+	for _, id := range approverIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalRuleParams validates request for an account owned by ownerID and
+// turns it into the row to store. Invalid fields are reported as a
+// *ValidationError.
+func (server *Server) approvalRuleParams(ctx context.Context, request *CreateApprovalRuleRequest, ownerID int64) (db.CreateApprovalRuleParams, error) {
+	params := db.CreateApprovalRuleParams{
+		AccountID:         request.AccountID,
+		RequiredApprovals: request.RequiredApprovals,
+		TtlSeconds:        request.TTLSeconds,
+	}
+	invalid := &ValidationError{}
+
+	if request.TokenAddress != "" {
+		if !common.IsHexAddress(request.TokenAddress) {
+			invalid.Add("token_address", "is not a valid address")
+		}
+		params.TokenAddress = pgtype.Text{String: strings.ToLower(request.TokenAddress), Valid: true}
+	}
+	if request.Threshold == nil || request.Threshold.Sign() < 0 {
+		invalid.Add("threshold", "must be a non-negative integer")
+	} else {
+		params.Threshold = store.BigToNumeric(request.Threshold)
+	}
+
+	seen := make(map[int64]bool, len(request.ApproverIDs))
+	for _, id := range request.ApproverIDs {
+		if seen[id] {
+			invalid.Add("approver_ids", fmt.Sprintf("approver %d is listed twice", id))
+			continue
+		}
+		seen[id] = true
+		// The owner starts every transaction, so letting them approve it
+		// too would defeat the point of a second pair of eyes.
+		if id == ownerID {
+			invalid.Add("approver_ids", "the account owner cannot be an approver")
+			continue
+		}
+		_, err := server.q.GetUserById(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			invalid.Add("approver_ids", fmt.Sprintf("approver %d does not exist", id))
+			continue
+		}
+		if err != nil {
+			return params, err
+		}
+		params.ApproverIds = append(params.ApproverIds, id)
+	}
+	if request.RequiredApprovals <= 0 || int(request.RequiredApprovals) > len(params.ApproverIds) {
+		invalid.Add("required_approvals", fmt.Sprintf("must be between 1 and the number of approvers (%d)", len(params.ApproverIds)))
+	}
+
+	if request.TTLSeconds < 0 {
+		invalid.Add("ttl_seconds", "must be positive")
+	}
+	if request.TTLSeconds == 0 {
+		params.TtlSeconds = int32(defaultApprovalTTL / time.Second)
+	}
+	if len(invalid.Fields) > 0 {
+		return params, invalid
+	}
+	return params, nil
+}
+
+// CreateApprovalRule makes transfers of an asset from one of the caller's
+// accounts wait for approval when their value exceeds the threshold. Each
+// account has at most one rule per asset.
+func (server *Server) CreateApprovalRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &CreateApprovalRuleRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	account, ok := server.authorizeAccount(w, r, request.AccountID)
+	if !ok {
+		return
+	}
+
+	params, err := server.approvalRuleParams(r.Context(), request, account.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	existing, err := server.q.GetApprovalRuleForAsset(r.Context(), db.GetApprovalRuleForAssetParams{
+		AccountID:    params.AccountID,
+		TokenAddress: params.TokenAddress,
+	})
+	if err == nil {
+		respond.WriteError(w, http.StatusConflict, codeDuplicateApprovalRule, fmt.Sprintf("Asset already has approval rule %d", existing.ID))
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, err)
+		return
+	}
+
+	rule, err := server.q.CreateApprovalRule(r.Context(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, newApprovalRuleResponse(rule))
+}
+
+// ListApprovalRules lists the approval rules of ?account_id=.
+func (server *Server) ListApprovalRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	accountID, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid account_id")
+		return
+	}
+
+	if _, ok := server.authorizeAccount(w, r, accountID); !ok {
+		return
+	}
+
+	rules, err := server.q.ListApprovalRulesByAccountId(r.Context(), accountID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListApprovalRulesResponse{Rules: make([]ApprovalRuleResponse, 0, len(rules))}
+	for _, rule := range rules {
+		response.Rules = append(response.Rules, newApprovalRuleResponse(rule))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// DeleteApprovalRule asks the approvers of a rule to agree to its deletion.
+// Dropping a rule loosens the account's protection, so the owner alone cannot
+// do it: the rule is deleted once required_approvals of its approvers approved
+// through ApproveApprovalRuleDeletion, or by an admin. The request expires
+// after the rule's TTL. Transactions already waiting for approval keep the
+// requirements they were created with.
+func (server *Server) DeleteApprovalRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ApprovalRuleRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	rule, err := server.q.GetApprovalRuleById(r.Context(), request.RuleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRuleNotFound, "Approval rule not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeAccount(w, r, rule.AccountID); !ok {
+		return
+	}
+
+	var deletion db.ApprovalRuleDeletion
+	err = server.store.ExecTx(r.Context(), func(q *db.Queries) error {
+		rule, err = q.LockApprovalRule(r.Context(), rule.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		deletion, err = q.CreateApprovalRuleDeletion(r.Context(), db.CreateApprovalRuleDeletionParams{
+			RuleID:      rule.ID,
+			RequestedBy: callerID(r.Context()),
+			ExpiresAt:   timestamp(now.Add(time.Duration(rule.TtlSeconds) * time.Second)),
+			Now:         timestamp(now),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			// A request is already open; asking again changes nothing.
+			deletion, err = q.GetApprovalRuleDeletion(r.Context(), rule.ID)
+		}
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusAccepted, newApprovalRuleDeletionResponse(rule, deletion, false))
+}
+
+// GetApprovalRuleDeletion shows the open deletion request of ?rule_id= to the
+// rule's owner and approvers.
+func (server *Server) GetApprovalRuleDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	ruleID, err := strconv.ParseInt(r.URL.Query().Get("rule_id"), 10, 64)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid rule_id")
+		return
+	}
+
+	rule, err := server.q.GetApprovalRuleById(r.Context(), ruleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRuleNotFound, "Approval rule not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if !isApprover(rule.ApproverIds, callerID(r.Context())) {
+		if _, ok := server.authorizeAccount(w, r, rule.AccountID); !ok {
+			return
+		}
+	}
+
+	deletion, err := server.q.GetApprovalRuleDeletion(r.Context(), rule.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRequestNotFound, "No deletion was requested for this rule")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newApprovalRuleDeletionResponse(rule, deletion, false))
+}
+
+// ApproveApprovalRuleDeletion records the caller's consent to deleting a rule
+// they approve for. The rule is deleted with the approval that completes the
+// quorum.
+func (server *Server) ApproveApprovalRuleDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ApprovalRuleRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	userID := callerID(r.Context())
+	var rule db.ApprovalRule
+	var deletion db.ApprovalRuleDeletion
+	var deleted bool
+	err = server.store.ExecTx(r.Context(), func(q *db.Queries) error {
+		// Lock the rule so concurrent approvals are counted one at a time.
+		rule, err = q.LockApprovalRule(r.Context(), request.RuleID)
+		if err != nil {
+			return err
+		}
+		if !isApprover(rule.ApproverIds, userID) {
+			return errNotApprover
+		}
+
+		deletion, err = q.GetApprovalRuleDeletion(r.Context(), rule.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errNoRuleDeletion
+		}
+		if err != nil {
+			return err
+		}
+		err = checkDeletionApproval(deletion, userID, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		deletion, err = q.SetApprovalRuleDeletionApprovals(r.Context(), db.SetApprovalRuleDeletionApprovalsParams{
+			RuleID:     rule.ID,
+			ApprovedBy: append(deletion.ApprovedBy, userID),
+		})
+		if err != nil {
+			return err
+		}
+
+		if int32(len(deletion.ApprovedBy)) < rule.RequiredApprovals {
+			return nil
+		}
+		deleted = true
+		return q.DeleteApprovalRule(r.Context(), rule.ID)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRuleNotFound, "Approval rule not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newApprovalRuleDeletionResponse(rule, deletion, deleted))
+}
+
+// checkDeletionApproval reports why userID, an approver of the rule, cannot
+// approve deletion at now. Whoever asked for the deletion does not count
+// towards its quorum.
+func checkDeletionApproval(deletion db.ApprovalRuleDeletion, userID int64, now time.Time) error {
+	if deletion.RequestedBy == userID {
+		return errNotApprover
+	}
+	if !now.Before(deletion.ExpiresAt.Time) {
+		return errApprovalExpired
+	}
+	if isApprover(deletion.ApprovedBy, userID) {
+		return errAlreadyDecided
+	}
+	return nil
+}
+
+// requestApproval puts tx on hold when a rule of its account requires
+// approval for its value, snapshotting the rule's approvers and quorum so
+// later rule changes do not affect it. It returns tx unchanged otherwise.
+func requestApproval(ctx context.Context, q *db.Queries, tx db.Transaction, now time.Time) (db.Transaction, error) {
+	token := pgtype.Text{String: strings.ToLower(tx.TokenAddress.String), Valid: tx.TokenAddress.Valid}
+	rule, err := q.GetApprovalRuleForAsset(ctx, db.GetApprovalRuleForAssetParams{
+		AccountID:    tx.AccountID,
+		TokenAddress: token,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return tx, nil
+	}
+	if err != nil {
+		return tx, err
+	}
+
+	if store.NumericToBig(tx.Value).Cmp(store.NumericToBig(rule.Threshold)) <= 0 {
+		return tx, nil
+	}
+
+	_, err = q.CreateApprovalRequest(ctx, db.CreateApprovalRequestParams{
+		TransactionID:     tx.ID,
+		RuleID:            pgtype.Int8{Int64: rule.ID, Valid: true},
+		RequiredApprovals: rule.RequiredApprovals,
+		ApproverIds:       rule.ApproverIds,
+		ExpiresAt:         timestamp(now.Add(time.Duration(rule.TtlSeconds) * time.Second)),
+	})
+	if err != nil {
+		return tx, err
+	}
+	return transitionTransaction(ctx, q, tx, TxStatusPendingApproval, nil)
+}
+
+// ApproveTransaction records the caller's approval of a transaction waiting
+// for it. The transaction becomes approved, and can be executed by its owner,
+// once the required number of approvers agreed.
+func (server *Server) ApproveTransaction(w http.ResponseWriter, r *http.Request) {
+	server.decideTransaction(w, r, ApprovalDecisionApprove)
+}
+
+// RejectTransaction records the caller's rejection of a transaction waiting
+// for approval. The transaction is rejected as soon as too few approvers are
+// left to reach the quorum.
+func (server *Server) RejectTransaction(w http.ResponseWriter, r *http.Request) {
+	server.decideTransaction(w, r, ApprovalDecisionReject)
+}
+
+// approvalOutcome returns the status the decisions so far move a transaction
+// waiting for request to: approved once the quorum agreed, rejected once too
+// few approvers are left to reach it, and "" while it is still open.
+func approvalOutcome(request db.ApprovalRequest, approvals []db.TransactionApproval) string {
+	var approved, rejected int32
+	for _, approval := range approvals {
+		if approval.Decision == ApprovalDecisionApprove {
+			approved++
+		} else {
+			rejected++
+		}
+	}
+
+	switch {
+	case approved >= request.RequiredApprovals:
+		return TxStatusApproved
+	case int32(len(request.ApproverIds))-rejected < request.RequiredApprovals:
+		return TxStatusRejected
+	}
+	return ""
+}
+
+func (server *Server) decideTransaction(w http.ResponseWriter, r *http.Request, decision string) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ApprovalDecisionRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	userID := callerID(r.Context())
+	var tx db.Transaction
+	var approvalRequest db.ApprovalRequest
+	var approvals []db.TransactionApproval
+	err = server.store.ExecTx(r.Context(), func(q *db.Queries) error {
+		// Lock the transaction so concurrent decisions are counted one at a
+		// time and exactly one of them moves it on.
+		tx, err = q.LockTransactionForApproval(r.Context(), request.TransactionID)
+		if err != nil {
+			return err
+		}
+		approvalRequest, err = q.GetApprovalRequest(r.Context(), tx.ID)
+		if err != nil {
+			return err
+		}
+		if !isApprover(approvalRequest.ApproverIds, userID) {
+			return errNotApprover
+		}
+		// Rules never list the owner, but the snapshot is checked anyway:
+		// whoever initiated a transaction must not count towards its quorum.
+		var account db.Account
+		account, err = q.GetAccountById(r.Context(), tx.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.UserID == userID {
+			return errNotApprover
+		}
+		if tx.Status != TxStatusPendingApproval {
+			return fmt.Errorf("%w: status is %s", errNotPendingApprove, tx.Status)
+		}
+		if !time.Now().UTC().Before(approvalRequest.ExpiresAt.Time) {
+			return errApprovalExpired
+		}
+
+		_, err = q.RecordApprovalDecision(r.Context(), db.RecordApprovalDecisionParams{
+			TransactionID: tx.ID,
+			UserID:        userID,
+			Decision:      decision,
+			Comment:       pgtype.Text{String: request.Comment, Valid: request.Comment != ""},
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errAlreadyDecided
+		}
+		if err != nil {
+			return err
+		}
+
+		approvals, err = q.ListTransactionApprovals(r.Context(), tx.ID)
+		if err != nil {
+			return err
+		}
+		switch approvalOutcome(approvalRequest, approvals) {
+		case TxStatusApproved:
+			tx, err = transitionTransaction(r.Context(), q, tx, TxStatusApproved, nil)
+		case TxStatusRejected:
+			tx, err = transitionTransaction(r.Context(), q, tx, TxStatusRejected, errRejectedByVote)
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRequestNotFound, "Approval request not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newApprovalRequestResponse(tx, approvalRequest, approvals))
+}
+
+// ExecuteTransaction signs and broadcasts an approved transaction. Only the
+// account owner can do so, since only they hold the wallet password.
+func (server *Server) ExecuteTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ExecuteTransactionRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	tx, err := server.q.GetTransactionById(r.Context(), request.TransactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeTransactionNotFound, "Transaction not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeAccount(w, r, tx.AccountID); !ok {
+		return
+	}
+
+	if tx.Status != TxStatusApproved {
+		respond.WriteError(w, http.StatusConflict, codeInvalidTransactionStatus, "Only approved transactions can be executed")
+		return
+	}
+
+	approvalRequest, err := server.q.GetApprovalRequest(r.Context(), tx.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !time.Now().UTC().Before(approvalRequest.ExpiresAt.Time) {
+		writeError(w, errApprovalExpired)
+		return
+	}
+
+	client, err := server.clients.Client(strconv.Itoa(int(tx.ChainID)))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	feeOpts := FeeOptions{
+		MaxFeePerGas:         request.MaxFeePerGas,
+		MaxPriorityFeePerGas: request.MaxPriorityFeePerGas,
+	}
+	record, err := server.makeTransaction(r.Context(), tx, request.Password, feeOpts, client)
+	if err != nil {
+		// Errors before signing, such as a wrong password, leave the
+		// approval intact for another try.
+		if record.Status != TxStatusApproved && neverSent(record, err) {
+			server.failTransaction(r.Context(), record, err)
+		}
+		writeError(w, err)
+		return
+	}
+
+	response := &CreateTransactionResponse{
+		Messsage:        "Transaction created!",
+		TransactionID:   record.ID,
+		TransactionHash: record.Hash.String,
+		ToAddress:       record.ToAddress,
+		Status:          record.Status,
+	}
+
+	respond.JSON(w, http.StatusCreated, *response)
+}
+
+// GetApproval returns the approval state and audit trail of
+// ?transaction_id=. It is visible to the account owner and the approvers.
+func (server *Server) GetApproval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(r.URL.Query().Get("transaction_id"), 10, 64)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid transaction_id")
+		return
+	}
+
+	approvalRequest, err := server.q.GetApprovalRequest(r.Context(), transactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeApprovalRequestNotFound, "Approval request not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	tx, err := server.q.GetTransactionById(r.Context(), transactionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if !isApprover(approvalRequest.ApproverIds, callerID(r.Context())) {
+		if _, ok := server.authorizeAccount(w, r, tx.AccountID); !ok {
+			return
+		}
+	}
+
+	approvals, err := server.q.ListTransactionApprovals(r.Context(), transactionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newApprovalRequestResponse(tx, approvalRequest, approvals))
+}
+
+// ListPendingApprovals lists the transactions waiting for the caller's
+// decision.
+func (server *Server) ListPendingApprovals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	txs, err := server.q.ListPendingApprovalsForApprover(r.Context(), callerID(r.Context()))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListPendingApprovalsResponse{Transactions: make([]TransactionResponse, 0, len(txs))}
+	for _, tx := range txs {
+		response.Transactions = append(response.Transactions, newTransactionResponse(tx))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// ExpireApprovals periodically expires transactions whose approval request
+// outlived its TTL, whether still waiting for approvers or approved but never
+// executed.
+func (server *Server) ExpireApprovals() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ticker := time.NewTicker(approvalSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		expired, err := server.q.ExpireApprovalRequests(context.Background(), timestamp(time.Now().UTC()))
+		if err != nil {
+			logger.Error("Failed to expire approval requests",
+				slog.Any("error", err),
+			)
+			continue
+		}
+		for _, tx := range expired {
+			logger.Info("Approval request expired",
+				slog.Int64("transaction_id", tx.ID),
+			)
+		}
+	}
+}
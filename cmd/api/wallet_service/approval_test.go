@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func decisions(list ...string) []db.TransactionApproval {
+	approvals := make([]db.TransactionApproval, len(list))
+	for i, decision := range list {
+		approvals[i] = db.TransactionApproval{UserID: int64(i + 2), Decision: decision}
+	}
+	return approvals
+}
+
+func TestApprovalOutcome(t *testing.T) {
+	const approve, reject = ApprovalDecisionApprove, ApprovalDecisionReject
+	twoOfThree := db.ApprovalRequest{RequiredApprovals: 2, ApproverIds: []int64{2, 3, 4}}
+	threeOfThree := db.ApprovalRequest{RequiredApprovals: 3, ApproverIds: []int64{2, 3, 4}}
+
+	tests := []struct {
+		name      string
+		request   db.ApprovalRequest
+		approvals []db.TransactionApproval
+		want      string
+	}{
+		{"no decisions", twoOfThree, nil, ""},
+		{"one approval short of quorum", twoOfThree, decisions(approve), ""},
+		{"quorum reached", twoOfThree, decisions(approve, approve), TxStatusApproved},
+		{"quorum reached despite a rejection", twoOfThree, decisions(reject, approve, approve), TxStatusApproved},
+		{"one rejection leaves quorum reachable", twoOfThree, decisions(reject), ""},
+		{"two rejections make quorum unreachable", twoOfThree, decisions(reject, reject), TxStatusRejected},
+		{"unanimous rule rejected by one", threeOfThree, decisions(approve, reject), TxStatusRejected},
+		{"unanimous rule approved by all", threeOfThree, decisions(approve, approve, approve), TxStatusApproved},
+	}
+	for _, tt := range tests {
+		got := approvalOutcome(tt.request, tt.approvals)
+		if got != tt.want {
+			t.Errorf("%s: outcome = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDeletionApproval(t *testing.T) {
+	now := time.Date(2024, 6, 12, 12, 0, 0, 0, time.UTC)
+	deletion := db.ApprovalRuleDeletion{
+		RuleID:      1,
+		RequestedBy: 1,
+		ApprovedBy:  []int64{2},
+		ExpiresAt:   timestamp(now.Add(time.Hour)),
+	}
+
+	tests := []struct {
+		name   string
+		userID int64
+		now    time.Time
+		want   error
+	}{
+		{"another approver", 3, now, nil},
+		{"requester", 1, now, errNotApprover},
+		{"approver who already agreed", 2, now, errAlreadyDecided},
+		{"after expiry", 3, now.Add(time.Hour), errApprovalExpired},
+	}
+	for _, tt := range tests {
+		err := checkDeletionApproval(deletion, tt.userID, tt.now)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRequestApproval(t *testing.T) {
+	now := time.Date(2024, 6, 12, 12, 0, 0, 0, time.UTC)
+	rule := db.ApprovalRule{
+		ID:                5,
+		AccountID:         1,
+		Threshold:         store.BigToNumeric(big.NewInt(1000)),
+		RequiredApprovals: 2,
+		ApproverIds:       []int64{2, 3, 4},
+		TtlSeconds:        3600,
+	}
+
+	tests := []struct {
+		name    string
+		rule    bool
+		value   int64
+		status  string
+		request bool
+	}{
+		{"no rule", false, 5000, TxStatusCreated, false},
+		{"below threshold", true, 999, TxStatusCreated, false},
+		{"at threshold", true, 1000, TxStatusCreated, false},
+		{"above threshold", true, 1001, TxStatusPendingApproval, true},
+	}
+	for _, tt := range tests {
+		var created *db.CreateApprovalRequestParams
+		fake := newFakeDB()
+		fake.on("GetApprovalRuleForAsset", func(args []any) ([]any, error) {
+			if !tt.rule {
+				return nil, pgx.ErrNoRows
+			}
+			return columns(rule), nil
+		})
+		fake.on("CreateApprovalRequest", func(args []any) ([]any, error) {
+			created = &db.CreateApprovalRequestParams{
+				TransactionID:     args[0].(int64),
+				RuleID:            args[1].(pgtype.Int8),
+				RequiredApprovals: args[2].(int32),
+				ApproverIds:       args[3].([]int64),
+				ExpiresAt:         args[4].(pgtype.Timestamp),
+			}
+			return columns(db.ApprovalRequest{TransactionID: created.TransactionID}), nil
+		})
+		fake.on("UpdateTransactionStatus", func(args []any) ([]any, error) {
+			return columns(db.Transaction{ID: args[2].(int64), Status: args[0].(string)}), nil
+		})
+
+		tx := db.Transaction{ID: 9, AccountID: 1, Status: TxStatusCreated, Value: store.BigToNumeric(big.NewInt(tt.value))}
+		got, err := requestApproval(context.Background(), db.New(fake), tx, now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got.Status != tt.status {
+			t.Errorf("%s: status = %q, want %q", tt.name, got.Status, tt.status)
+		}
+		if (created != nil) != tt.request {
+			t.Fatalf("%s: approval request created = %v, want %v", tt.name, created != nil, tt.request)
+		}
+		if created == nil {
+			continue
+		}
+		if created.RequiredApprovals != rule.RequiredApprovals || len(created.ApproverIds) != len(rule.ApproverIds) || created.RuleID.Int64 != rule.ID {
+			t.Errorf("%s: request = %+v, want a snapshot of rule %d", tt.name, *created, rule.ID)
+		}
+		if !created.ExpiresAt.Time.Equal(now.Add(time.Hour)) {
+			t.Errorf("%s: request expires at %v, want %v", tt.name, created.ExpiresAt.Time, now.Add(time.Hour))
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type contextKey string
+
+const userIDContextKey contextKey = "user_id"
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RefreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SessionResponse struct {
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// newToken returns a random opaque token and the hash under which it is stored.
+// Only the hash is persisted, so a database leak does not expose live tokens.
+func newToken() (string, string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
+
+// newSessionTokens issues a fresh access/refresh token pair. The returned
+// response carries the raw tokens; the params carry only their hashes.
+func (server *Server) newSessionTokens() (*SessionResponse, db.RotateSessionParams, error) {
+	accessToken, accessHash, err := newToken()
+	if err != nil {
+		return nil, db.RotateSessionParams{}, err
+	}
+
+	refreshToken, refreshHash, err := newToken()
+	if err != nil {
+		return nil, db.RotateSessionParams{}, err
+	}
+
+	now := time.Now().UTC()
+	response := &SessionResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  now.Add(server.config.AccessTokenDuration),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: now.Add(server.config.RefreshTokenDuration),
+	}
+	params := db.RotateSessionParams{
+		AccessTokenHash:  accessHash,
+		RefreshTokenHash: refreshHash,
+		AccessExpiresAt:  timestamp(response.AccessTokenExpiresAt),
+		RefreshExpiresAt: timestamp(response.RefreshTokenExpiresAt),
+	}
+	return response, params, nil
+}
+
+func (server *Server) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	login := &LoginRequest{}
+	err := json.NewDecoder(r.Body).Decode(login)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	user, err := server.q.GetUserByEmail(r.Context(), login.Email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusUnauthorized, codeInvalidCredentials, "Invalid email or password")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.WalletHashPassword), []byte(login.Password))
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, codeInvalidCredentials, "Invalid email or password")
+		return
+	}
+
+	response, tokens, err := server.newSessionTokens()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	_, err = server.q.CreateSession(r.Context(), db.CreateSessionParams{
+		UserID:           user.ID,
+		AccessTokenHash:  tokens.AccessTokenHash,
+		RefreshTokenHash: tokens.RefreshTokenHash,
+		AccessExpiresAt:  tokens.AccessExpiresAt,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// RefreshSession exchanges a valid refresh token for a new token pair. Both
+// tokens are rotated so a refresh token can only be used once.
+func (server *Server) RefreshSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	refresh := &RefreshSessionRequest{}
+	err := json.NewDecoder(r.Body).Decode(refresh)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	session, err := server.q.GetSessionByRefreshTokenHash(r.Context(), hashToken(refresh.RefreshToken))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusUnauthorized, codeInvalidToken, "Invalid refresh token")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if time.Now().UTC().After(session.RefreshExpiresAt.Time) {
+		respond.WriteError(w, http.StatusUnauthorized, codeTokenExpired, "Refresh token expired")
+		return
+	}
+
+	response, tokens, err := server.newSessionTokens()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Matching on the old hash makes the rotation a compare-and-swap: of two
+	// concurrent refreshes with the same token only one updates the row.
+	tokens.ID = session.ID
+	tokens.OldRefreshTokenHash = session.RefreshTokenHash
+	_, err = server.q.RotateSession(r.Context(), tokens)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusUnauthorized, codeInvalidToken, "Invalid refresh token")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+func (server *Server) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	session, err := server.q.GetSessionByAccessTokenHash(r.Context(), hashToken(bearerToken(r)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusUnauthorized, codeInvalidToken, "Invalid access token")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	err = server.q.DeleteSession(r.Context(), session.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// authenticate resolves the bearer access token to a user and stores the user
+// ID on the request context for the wrapped handler.
+func (server *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := bearerToken(r)
+		if token == "" {
+			respond.WriteError(w, http.StatusUnauthorized, respond.CodeUnauthorized, "Missing access token")
+			return
+		}
+
+		session, err := server.q.GetSessionByAccessTokenHash(r.Context(), hashToken(token))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				respond.WriteError(w, http.StatusUnauthorized, codeInvalidToken, "Invalid access token")
+				return
+			}
+			respond.Internal(w, err)
+			return
+		}
+
+		if time.Now().UTC().After(session.AccessExpiresAt.Time) {
+			respond.WriteError(w, http.StatusUnauthorized, codeTokenExpired, "Access token expired")
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userIDContextKey, session.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// callerID returns the authenticated user ID set by authenticate.
+func callerID(ctx context.Context) int64 {
+	userID, _ := ctx.Value(userIDContextKey).(int64)
+	return userID
+}
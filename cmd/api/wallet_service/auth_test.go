@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cf "github.com/Dev317/golang_wallet/config/wallet"
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/jackc/pgx/v5"
+)
+
+// sessionStore serves the session queries of RefreshSession from a single
+// row, applying RotateSession only while the old refresh token hash matches.
+func sessionStore(fake *fakeDB, session *db.Session) {
+	var mu sync.Mutex
+	fake.on("GetSessionByRefreshTokenHash", func(args []any) ([]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if args[0].(string) != session.RefreshTokenHash {
+			return nil, pgx.ErrNoRows
+		}
+		return columns(*session), nil
+	})
+	fake.on("RotateSession", func(args []any) ([]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if args[4].(int64) != session.ID || args[5].(string) != session.RefreshTokenHash {
+			return nil, pgx.ErrNoRows
+		}
+		session.AccessTokenHash = args[0].(string)
+		session.RefreshTokenHash = args[1].(string)
+		return columns(*session), nil
+	})
+}
+
+func refresh(server *Server, token string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(RefreshSessionRequest{RefreshToken: token})
+	recorder := httptest.NewRecorder()
+	server.RefreshSession(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewReader(body)))
+	return recorder
+}
+
+func errorCode(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	var response respond.ErrorResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("decoding error response %q: %v", recorder.Body.String(), err)
+	}
+	return response.Error.Code
+}
+
+func newSessionServer(fake *fakeDB) *Server {
+	return &Server{
+		config: cf.Config{AccessTokenDuration: time.Minute, RefreshTokenDuration: time.Hour},
+		q:      db.New(fake),
+	}
+}
+
+func TestRefreshSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		expiresIn time.Duration
+		status    int
+		code      string
+	}{
+		{"current token", "refresh-token", time.Hour, http.StatusOK, ""},
+		{"unknown token", "other-token", time.Hour, http.StatusUnauthorized, codeInvalidToken},
+		{"expired token", "refresh-token", -time.Minute, http.StatusUnauthorized, codeTokenExpired},
+	}
+	for _, tt := range tests {
+		session := &db.Session{
+			ID:               1,
+			UserID:           7,
+			RefreshTokenHash: hashToken("refresh-token"),
+			RefreshExpiresAt: timestamp(time.Now().UTC().Add(tt.expiresIn)),
+		}
+		fake := newFakeDB()
+		sessionStore(fake, session)
+
+		recorder := refresh(newSessionServer(fake), tt.token)
+		if recorder.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, tt.status)
+			continue
+		}
+		if tt.code != "" {
+			if code := errorCode(t, recorder); code != tt.code {
+				t.Errorf("%s: code = %q, want %q", tt.name, code, tt.code)
+			}
+			continue
+		}
+
+		var response SessionResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		if err != nil {
+			t.Fatalf("%s: decoding response: %v", tt.name, err)
+		}
+		if session.RefreshTokenHash != hashToken(response.RefreshToken) {
+			t.Errorf("%s: stored refresh token was not rotated to the issued one", tt.name)
+		}
+	}
+}
+
+func TestRefreshSessionRotatesOnce(t *testing.T) {
+	session := &db.Session{
+		ID:               1,
+		UserID:           7,
+		RefreshTokenHash: hashToken("refresh-token"),
+		RefreshExpiresAt: timestamp(time.Now().UTC().Add(time.Hour)),
+	}
+	fake := newFakeDB()
+	sessionStore(fake, session)
+
+	// Both requests look the session up before either rotates it, as two
+	// concurrent refreshes with the same token would.
+	const requests = 2
+	var read sync.WaitGroup
+	read.Add(requests)
+	lookup := fake.handlers["GetSessionByRefreshTokenHash"]
+	fake.on("GetSessionByRefreshTokenHash", func(args []any) ([]any, error) {
+		row, err := lookup(args)
+		read.Done()
+		read.Wait()
+		return row, err
+	})
+
+	server := newSessionServer(fake)
+	statuses := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			statuses <- refresh(server, "refresh-token").Code
+		}()
+	}
+
+	counts := map[int]int{}
+	for i := 0; i < requests; i++ {
+		counts[<-statuses]++
+	}
+	fake.on("GetSessionByRefreshTokenHash", lookup)
+	if counts[http.StatusOK] != 1 || counts[http.StatusUnauthorized] != 1 {
+		t.Errorf("statuses = %v, want one success and one rejection", counts)
+	}
+
+	if code := errorCode(t, refresh(server, "refresh-token")); code != codeInvalidToken {
+		t.Errorf("reusing a rotated token: code = %q, want %q", code, codeInvalidToken)
+	}
+}
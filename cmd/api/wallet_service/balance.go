@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+
+	cf "github.com/Dev317/golang_wallet/config/wallet"
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// defaultMulticallAddress is the canonical Multicall3 deployment, available at
+// the same address on most EVM chains.
+const defaultMulticallAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// maxMulticallBatch bounds the number of calls aggregated into one eth_call.
+const maxMulticallBatch = 500
+
+const multicallABIJSON = `[
+	{"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]},
+	{"type":"function","name":"getEthBalance","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}
+]`
+
+var multicallABI = mustParseABI(multicallABIJSON)
+
+var errMulticallUnavailable = errors.New("multicall contract not deployed")
+
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type TokenBalance struct {
+	Symbol   string `json:"symbol"`
+	Address  string `json:"address"`
+	Decimals uint8  `json:"decimals"`
+	Balance  string `json:"balance,omitempty"`
+}
+
+type AccountBalanceResponse struct {
+	AccountID int64          `json:"account_id"`
+	Address   string         `json:"address"`
+	ChainID   int32          `json:"chain_id"`
+	Balance   string         `json:"balance"`
+	Tokens    []TokenBalance `json:"tokens"`
+}
+
+type ListAccountsResponse struct {
+	Accounts []AccountBalanceResponse `json:"accounts"`
+}
+
+// accountBalances holds the balances read for one address: the native
+// balance and one entry per configured token, nil where a read failed.
+type accountBalances struct {
+	Native *big.Int
+	Tokens []*big.Int
+}
+
+// fetchBalances reads the native and token balances of every address. All
+// reads are batched through Multicall3 so the RPC cost does not grow with the
+// number of accounts and tokens; chains without the contract fall back to
+// individual calls.
+func fetchBalances(ctx context.Context, client *ethclient.Client, chainItem cf.ChainItemConfig, addresses []common.Address) ([]accountBalances, error) {
+	multicall := common.HexToAddress(defaultMulticallAddress)
+	if chainItem.MulticallAddress != "" {
+		multicall = common.HexToAddress(chainItem.MulticallAddress)
+	}
+
+	var calls []multicallCall
+	for _, address := range addresses {
+		data, err := multicallABI.Pack("getEthBalance", address)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, multicallCall{Target: multicall, AllowFailure: true, CallData: data})
+
+		for _, token := range chainItem.Tokens {
+			data, err := erc20ABI.Pack("balanceOf", address)
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, multicallCall{Target: common.HexToAddress(token.Address), AllowFailure: true, CallData: data})
+		}
+	}
+
+	results, err := aggregate(ctx, client, multicall, calls)
+	if errors.Is(err, errMulticallUnavailable) {
+		results, err = callEach(ctx, client, calls)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]accountBalances, len(addresses))
+	stride := 1 + len(chainItem.Tokens)
+	for i := range addresses {
+		row := results[i*stride : (i+1)*stride]
+		balances[i].Native = decodeUint256(multicallABI, "getEthBalance", row[0])
+		for _, result := range row[1:] {
+			balances[i].Tokens = append(balances[i].Tokens, decodeUint256(erc20ABI, "balanceOf", result))
+		}
+	}
+	return balances, nil
+}
+
+// aggregate executes calls through Multicall3 in batches of maxMulticallBatch.
+func aggregate(ctx context.Context, client *ethclient.Client, multicall common.Address, calls []multicallCall) ([]multicallResult, error) {
+	results := make([]multicallResult, 0, len(calls))
+	for start := 0; start < len(calls); start += maxMulticallBatch {
+		batch := calls[start:min(start+maxMulticallBatch, len(calls))]
+
+		data, err := multicallABI.Pack("aggregate3", batch)
+		if err != nil {
+			return nil, err
+		}
+		output, err := client.CallContract(ctx, ethereum.CallMsg{To: &multicall, Data: data}, nil)
+		if err != nil {
+			return nil, err
+		}
+		// Calling an address without code succeeds with no return data.
+		if len(output) == 0 {
+			return nil, errMulticallUnavailable
+		}
+
+		values, err := multicallABI.Unpack("aggregate3", output)
+		if err != nil {
+			return nil, err
+		}
+		batchResults := *abi.ConvertType(values[0], new([]multicallResult)).(*[]multicallResult)
+		results = append(results, batchResults...)
+	}
+	return results, nil
+}
+
+// callEach executes calls one at a time. getEthBalance calls addressed to the
+// missing multicall contract are served with BalanceAt instead.
+func callEach(ctx context.Context, client *ethclient.Client, calls []multicallCall) ([]multicallResult, error) {
+	getEthBalance := multicallABI.Methods["getEthBalance"]
+
+	results := make([]multicallResult, len(calls))
+	for i, call := range calls {
+		if len(call.CallData) >= 4 && bytes.Equal(call.CallData[:4], getEthBalance.ID) {
+			args, err := getEthBalance.Inputs.Unpack(call.CallData[4:])
+			if err != nil {
+				return nil, err
+			}
+			balance, err := client.BalanceAt(ctx, args[0].(common.Address), nil)
+			if err != nil {
+				return nil, err
+			}
+			output, err := getEthBalance.Outputs.Pack(balance)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = multicallResult{Success: true, ReturnData: output}
+			continue
+		}
+
+		target := call.Target
+		output, err := client.CallContract(ctx, ethereum.CallMsg{To: &target, Data: call.CallData}, nil)
+		results[i] = multicallResult{Success: err == nil, ReturnData: output}
+	}
+	return results, nil
+}
+
+func decodeUint256(contract abi.ABI, method string, result multicallResult) *big.Int {
+	if !result.Success {
+		return nil
+	}
+	values, err := contract.Unpack(method, result.ReturnData)
+	if err != nil {
+		return nil
+	}
+	return values[0].(*big.Int)
+}
+
+// accountBalanceResponses reads live balances for accounts, which may span
+// several chains. Each chain is read with a single batch.
+// Accounts on chains that are not configured report their stored balance.
+func (server *Server) accountBalanceResponses(ctx context.Context, accounts []db.Account) ([]AccountBalanceResponse, error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	responses := make([]AccountBalanceResponse, len(accounts))
+	byChain := map[int32][]int{}
+	for i, account := range accounts {
+		responses[i] = AccountBalanceResponse{
+			AccountID: account.ID,
+			Address:   account.Address,
+			ChainID:   account.ChainID,
+			Balance:   store.NumericString(account.Balance),
+			Tokens:    []TokenBalance{},
+		}
+		byChain[account.ChainID] = append(byChain[account.ChainID], i)
+	}
+
+	for chainID, indexes := range byChain {
+		chainItem, ok := server.chainConfig(strconv.Itoa(int(chainID)))
+		if !ok {
+			logger.Warn("Chain is not configured, using stored balances",
+				slog.Int("chain_id", int(chainID)),
+			)
+			continue
+		}
+
+		client, err := server.clients.Client(chainItem.ChainID)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses := make([]common.Address, len(indexes))
+		for j, i := range indexes {
+			addresses[j] = common.HexToAddress(accounts[i].Address)
+		}
+
+		balances, err := fetchBalances(ctx, client, chainItem, addresses)
+		if err != nil {
+			return nil, err
+		}
+
+		for j, i := range indexes {
+			if balances[j].Native != nil {
+				responses[i].Balance = balances[j].Native.String()
+			}
+			for k, token := range chainItem.Tokens {
+				tokenBalance := TokenBalance{
+					Symbol:   token.Symbol,
+					Address:  token.Address,
+					Decimals: token.Decimals,
+				}
+				if balance := balances[j].Tokens[k]; balance != nil {
+					tokenBalance.Balance = balance.String()
+				}
+				responses[i].Tokens = append(responses[i].Tokens, tokenBalance)
+			}
+		}
+	}
+	return responses, nil
+}
+
+// GetBalance returns the native and configured token balances of the account
+// given by ?account_id=.
+func (server *Server) GetBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	accountID, err := strconv.ParseInt(r.URL.Query().Get("account_id"), 10, 64)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid account_id")
+		return
+	}
+
+	account, ok := server.authorizeAccount(w, r, accountID)
+	if !ok {
+		return
+	}
+
+	responses, err := server.accountBalanceResponses(r.Context(), []db.Account{account})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, responses[0])
+}
+
+// ListAccounts returns the caller's accounts with their balances.
+func (server *Server) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	accounts, err := server.q.GetAccountByUserId(r.Context(), callerID(r.Context()))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	responses, err := server.accountBalanceResponses(r.Context(), accounts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, ListAccountsResponse{Accounts: responses})
+}
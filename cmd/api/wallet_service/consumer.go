@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"os"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/queue"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// resultStatusReverted marks a result that sends a mined transaction back to
+// broadcast after a reorg; it is not a transaction state itself.
+const resultStatusReverted = "reverted"
+
+var (
+	errTxReverted = errors.New("transaction reverted on-chain")
+	errTxDropped  = errors.New("transaction dropped from the network")
+	errTxReplaced = errors.New("transaction replaced")
+)
+
+// transactionResult is a scanner report about a submitted transaction,
+// flattened from its event into the status it moves the transaction to.
+type transactionResult struct {
+	Envelope        event.Envelope
+	TransactionID   int64
+	TransactionHash string
+	Status          string
+	BlockNumber     uint64
+	BlockHash       string
+	Balance         string
+	ReplacedBy      string
+}
+
+// failureStatuses maps the reason a transaction failed to its final status.
+var failureStatuses = map[string]string{
+	event.FailureExecutionReverted: TxStatusFailed,
+	event.FailureDropped:           TxStatusDropped,
+	event.FailureReplaced:          TxStatusReplaced,
+}
+
+func newTransactionResult(envelope event.Envelope, payload event.Payload) (transactionResult, error) {
+	result := transactionResult{Envelope: envelope}
+	switch p := payload.(type) {
+	case *event.TransactionConfirmed:
+		result.TransactionID = p.TransactionID
+		result.TransactionHash = p.TransactionHash
+		result.BlockNumber = p.BlockNumber
+		result.BlockHash = p.BlockHash
+		result.Balance = p.Balance
+		switch envelope.Type {
+		case event.TypeTransactionMined:
+			result.Status = TxStatusMined
+		case event.TypeTransactionConfirmed:
+			result.Status = TxStatusConfirmed
+		default:
+			result.Status = resultStatusReverted
+		}
+	case *event.TransactionFailed:
+		result.TransactionID = p.TransactionID
+		result.TransactionHash = p.TransactionHash
+		result.BlockNumber = p.BlockNumber
+		result.BlockHash = p.BlockHash
+		result.Balance = p.Balance
+		result.ReplacedBy = p.ReplacedBy
+		result.Status = failureStatuses[p.Reason]
+	default:
+		return transactionResult{}, fmt.Errorf("%w: %s on %s", event.ErrUnknownType, envelope.Type, event.ResultQueue)
+	}
+	return result, nil
+}
+
+// Consume delivers every message on queueName to handle. Messages are
+// acknowledged once handled and dead-lettered after repeated failures. The
+// subscription survives reconnects to the broker.
+func (server *Server) Consume(queueName string, handle queue.Handler) {
+	server.bus.Subscribe(queueName, queue.DefaultPrefetch, queue.DefaultRetryPolicy, handle)
+}
+
+func (server *Server) handleTransactionResult(ctx context.Context, body []byte) error {
+	envelope, payload, err := event.Parse(body)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	result, err := newTransactionResult(envelope, payload)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	return server.applyTransactionResult(ctx, result)
+}
+
+// handleDeposit notifies the receiving account's webhooks and refreshes its
+// native balance once a native deposit is confirmed or reverted.
+func (server *Server) handleDeposit(ctx context.Context, body []byte) error {
+	envelope, payload, err := event.Parse(body)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	deposit, ok := payload.(*event.DepositDetected)
+	if !ok {
+		return queue.Permanent(fmt.Errorf("%w: %s on %s", event.ErrUnknownType, envelope.Type, event.DepositQueue))
+	}
+
+	var balance *big.Int
+	if envelope.Type != event.TypeDepositDetected && deposit.TokenAddress == "" && deposit.Balance != "" {
+		balance, ok = new(big.Int).SetString(deposit.Balance, 10)
+		if !ok {
+			return queue.Permanent(fmt.Errorf("malformed balance %q", deposit.Balance))
+		}
+	}
+
+	account, err := server.q.GetAccountById(ctx, deposit.AccountID)
+	if err != nil {
+		return err
+	}
+
+	return server.store.ExecTx(ctx, func(q *db.Queries) error {
+		fresh, err := store.MarkProcessed(ctx, q, event.DepositQueue, envelope.ID)
+		if err != nil || !fresh {
+			return err
+		}
+
+		err = enqueueWebhooks(ctx, q, account.UserID, envelope)
+		if err != nil || balance == nil {
+			return err
+		}
+		return q.UpdateAccountBalance(ctx, db.UpdateAccountBalanceParams{
+			Address: account.Address,
+			ChainID: account.ChainID,
+			Balance: store.BigToNumeric(balance),
+		})
+	})
+}
+
+// resultTransitions returns the statuses a transaction must pass through to
+// reach the reported status. A confirmation can arrive for a transaction we
+// never saw mined, e.g. when it confirmed between two scanner polls, and a
+// reverted report sends a mined transaction back to broadcast. A result for a
+// transaction still recorded as signed means the broadcast was never recorded,
+// so it moves through broadcast first. A failed transaction stays failed: the
+// scanner still watches the hash of a send the node rejected.
+func resultTransitions(current, reported string) []string {
+	if current == TxStatusFailed {
+		return nil
+	}
+	if current == TxStatusSigned && reported != resultStatusReverted {
+		return append([]string{TxStatusBroadcast}, resultTransitions(TxStatusBroadcast, reported)...)
+	}
+	if reported == resultStatusReverted {
+		if current == TxStatusMined || current == TxStatusConfirmed {
+			return []string{TxStatusBroadcast}
+		}
+		return nil
+	}
+	if current == reported {
+		return nil
+	}
+	if current == TxStatusBroadcast && reported == TxStatusConfirmed {
+		return []string{TxStatusMined, TxStatusConfirmed}
+	}
+	return []string{reported}
+}
+
+func (server *Server) applyTransactionResult(ctx context.Context, result transactionResult) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	tx, err := server.q.GetTransactionById(ctx, result.TransactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Hash.String != result.TransactionHash {
+		logger.Warn("Ignoring result for mismatched transaction hash",
+			slog.Int64("transaction_id", tx.ID),
+			slog.String("tx_hash", result.TransactionHash),
+		)
+		return nil
+	}
+
+	return server.store.ExecTx(ctx, func(q *db.Queries) error {
+		fresh, err := store.MarkProcessed(ctx, q, event.ResultQueue, result.Envelope.ID)
+		if err != nil || !fresh {
+			return err
+		}
+
+		account, err := q.GetAccountById(ctx, tx.AccountID)
+		if err != nil {
+			return err
+		}
+		err = enqueueWebhooks(ctx, q, account.UserID, result.Envelope)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range resultTransitions(tx.Status, result.Status) {
+			var cause error
+			switch status {
+			case TxStatusFailed:
+				cause = errTxReverted
+			case TxStatusDropped:
+				cause = errTxDropped
+			case TxStatusReplaced:
+				cause = fmt.Errorf("%w by %s", errTxReplaced, result.ReplacedBy)
+			}
+
+			next, err := transitionTransaction(ctx, q, tx, status, cause)
+			if err != nil {
+				return err
+			}
+			tx = next
+		}
+
+		var blockNumber pgtype.Int8
+		var blockHash pgtype.Text
+		if result.Status != resultStatusReverted && result.BlockHash != "" {
+			blockNumber = pgtype.Int8{Int64: int64(result.BlockNumber), Valid: true}
+			blockHash = pgtype.Text{String: result.BlockHash, Valid: true}
+		}
+		if blockHash != tx.BlockHash {
+			err := q.SetTransactionBlock(ctx, db.SetTransactionBlockParams{
+				ID:          tx.ID,
+				BlockNumber: blockNumber,
+				BlockHash:   blockHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if result.Balance == "" {
+			return nil
+		}
+		balance, ok := new(big.Int).SetString(result.Balance, 10)
+		if !ok {
+			logger.Warn("Ignoring malformed balance",
+				slog.String("balance", result.Balance),
+			)
+			return nil
+		}
+		return q.UpdateAccountBalance(ctx, db.UpdateAccountBalanceParams{
+			Address: tx.FromAddress,
+			ChainID: tx.ChainID,
+			Balance: store.BigToNumeric(balance),
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResultTransitions(t *testing.T) {
+	tests := []struct {
+		current  string
+		reported string
+		want     []string
+	}{
+		{TxStatusBroadcast, TxStatusMined, []string{TxStatusMined}},
+		{TxStatusBroadcast, TxStatusConfirmed, []string{TxStatusMined, TxStatusConfirmed}},
+		{TxStatusMined, TxStatusConfirmed, []string{TxStatusConfirmed}},
+		{TxStatusMined, TxStatusMined, nil},
+		{TxStatusMined, resultStatusReverted, []string{TxStatusBroadcast}},
+		{TxStatusConfirmed, resultStatusReverted, []string{TxStatusBroadcast}},
+		{TxStatusBroadcast, resultStatusReverted, nil},
+		{TxStatusSigned, TxStatusMined, []string{TxStatusBroadcast, TxStatusMined}},
+		{TxStatusSigned, TxStatusConfirmed, []string{TxStatusBroadcast, TxStatusMined, TxStatusConfirmed}},
+		{TxStatusSigned, TxStatusDropped, []string{TxStatusBroadcast, TxStatusDropped}},
+		{TxStatusSigned, resultStatusReverted, nil},
+		{TxStatusFailed, TxStatusDropped, nil},
+		{TxStatusFailed, TxStatusReplaced, nil},
+	}
+	for _, tt := range tests {
+		got := resultTransitions(tt.current, tt.reported)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("resultTransitions(%s, %s) = %v, want %v", tt.current, tt.reported, got, tt.want)
+		}
+	}
+}
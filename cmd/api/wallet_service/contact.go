@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxContactLabelLength = 100
+
+var (
+	errInvalidDestination = errors.New("invalid destination")
+	errContactNotFound    = errors.New("contact not found")
+	errContactCoolingOff  = errors.New("contact is still in its cooling-off period")
+)
+
+type CreateContactRequest struct {
+	ChainID           int32  `json:"chain_id"`
+	Label             string `json:"label"`
+	Address           string `json:"address"`
+	CoolingOffSeconds int32  `json:"cooling_off_seconds,omitempty"`
+}
+
+type UpdateContactRequest struct {
+	ContactID int64  `json:"contact_id"`
+	Label     string `json:"label"`
+}
+
+type ContactRequest struct {
+	ContactID int64 `json:"contact_id"`
+}
+
+type ContactResponse struct {
+	ID        int64     `json:"id"`
+	ChainID   int32     `json:"chain_id"`
+	Label     string    `json:"label"`
+	Address   string    `json:"address"`
+	UsableAt  time.Time `json:"usable_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListContactsResponse struct {
+	Contacts []ContactResponse `json:"contacts"`
+}
+
+func newContactResponse(c db.Contact) ContactResponse {
+	return ContactResponse{
+		ID:        c.ID,
+		ChainID:   c.ChainID,
+		Label:     c.Label,
+		Address:   c.Address,
+		UsableAt:  c.UsableAt.Time,
+		CreatedAt: c.CreatedAt.Time,
+	}
+}
+
+// parseAddress validates a destination address. Unlike common.HexToAddress it
+// rejects malformed input instead of mapping it to the zero address, and it
+// requires the EIP-55 checksum so that a mistyped character is caught.
+func parseAddress(raw string) (common.Address, error) {
+	if !common.IsHexAddress(raw) {
+		return common.Address{}, fmt.Errorf("%w: %q is not a hex address", errInvalidDestination, raw)
+	}
+
+	address := common.HexToAddress(raw)
+	if address.Hex() != raw {
+		return common.Address{}, fmt.Errorf("%w: %q is not an EIP-55 checksummed address", errInvalidDestination, raw)
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: zero address", errInvalidDestination)
+	}
+	return address, nil
+}
+
+func validateContactLabel(label string) (string, error) {
+	label = strings.TrimSpace(label)
+	if label == "" || len(label) > maxContactLabelLength {
+		return "", fmt.Errorf("must be 1-%d characters", maxContactLabelLength)
+	}
+	return label, nil
+}
+
+// resolveDestination returns the address a transfer from account goes to:
+// either toAddress, or the address of the caller's saved contact contactID.
+func (server *Server) resolveDestination(ctx context.Context, account db.Account, toAddress string, contactID *int64) (string, error) {
+	switch {
+	case contactID != nil && toAddress != "":
+		return "", fmt.Errorf("%w: give either to_address or contact_id, not both", errInvalidDestination)
+	case contactID == nil:
+		address, err := parseAddress(toAddress)
+		if err != nil {
+			return "", err
+		}
+		return address.Hex(), nil
+	}
+
+	contact, err := server.q.GetContactById(ctx, *contactID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && contact.UserID != callerID(ctx)) {
+		return "", errContactNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if contact.ChainID != account.ChainID {
+		return "", fmt.Errorf("%w: contact %d is on chain %d, not %d", errInvalidDestination, contact.ID, contact.ChainID, account.ChainID)
+	}
+	if time.Now().UTC().Before(contact.UsableAt.Time) {
+		return "", fmt.Errorf("%w: usable from %s", errContactCoolingOff, contact.UsableAt.Time.Format(time.RFC3339))
+	}
+	return contact.Address, nil
+}
+
+// CreateContact saves an address to the caller's address book. With a
+// cooling_off_seconds the contact cannot be sent to until that much time has
+// passed, which gives the user a chance to notice a contact they did not add.
+func (server *Server) CreateContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &CreateContactRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	invalid := &ValidationError{}
+	label, err := validateContactLabel(request.Label)
+	if err != nil {
+		invalid.Add("label", err.Error())
+	}
+	address, err := parseAddress(request.Address)
+	if err != nil {
+		invalid.Add("address", err.Error())
+	}
+	server.validateChain(invalid, "chain_id", strconv.Itoa(int(request.ChainID)))
+	if request.CoolingOffSeconds < 0 {
+		invalid.Add("cooling_off_seconds", "must not be negative")
+	}
+	if invalid.orNil() != nil {
+		writeError(w, invalid)
+		return
+	}
+
+	usableAt := time.Now().UTC().Add(time.Duration(request.CoolingOffSeconds) * time.Second)
+	contact, err := server.q.CreateContact(r.Context(), db.CreateContactParams{
+		UserID:   callerID(r.Context()),
+		ChainID:  request.ChainID,
+		Label:    label,
+		Address:  address.Hex(),
+		UsableAt: timestamp(usableAt),
+	})
+	if err != nil {
+		if respond.IsUniqueViolation(err) {
+			respond.WriteError(w, http.StatusConflict, codeDuplicateContact, "A contact with this label or address already exists")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, newContactResponse(contact))
+}
+
+func (server *Server) ListContacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	contacts, err := server.q.ListContactsByUserId(r.Context(), callerID(r.Context()))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListContactsResponse{Contacts: make([]ContactResponse, 0, len(contacts))}
+	for _, contact := range contacts {
+		response.Contacts = append(response.Contacts, newContactResponse(contact))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// UpdateContact renames a contact. Its address cannot be changed, since that
+// would bypass the cooling-off period; delete and re-create it instead.
+func (server *Server) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &UpdateContactRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	label, err := validateContactLabel(request.Label)
+	if err != nil {
+		invalid := &ValidationError{}
+		invalid.Add("label", err.Error())
+		writeError(w, invalid)
+		return
+	}
+
+	contact, ok := server.authorizeContact(w, r, request.ContactID)
+	if !ok {
+		return
+	}
+
+	contact, err = server.q.UpdateContactLabel(r.Context(), db.UpdateContactLabelParams{
+		ID:    contact.ID,
+		Label: label,
+	})
+	if err != nil {
+		if respond.IsUniqueViolation(err) {
+			respond.WriteError(w, http.StatusConflict, codeDuplicateContact, "A contact with this label already exists")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newContactResponse(contact))
+}
+
+func (server *Server) DeleteContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ContactRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	contact, ok := server.authorizeContact(w, r, request.ContactID)
+	if !ok {
+		return
+	}
+
+	err = server.q.DeleteContact(r.Context(), contact.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeContact loads the contact and checks that it belongs to the
+// caller. It writes the error response itself and returns false on failure.
+func (server *Server) authorizeContact(w http.ResponseWriter, r *http.Request, contactID int64) (db.Contact, bool) {
+	contact, err := server.q.GetContactById(r.Context(), contactID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeContactNotFound, "Contact not found")
+			return contact, false
+		}
+		writeError(w, err)
+		return contact, false
+	}
+
+	if contact.UserID != callerID(r.Context()) {
+		respond.WriteError(w, http.StatusForbidden, respond.CodeForbidden, "Contact does not belong to caller")
+		return contact, false
+	}
+	return contact, true
+}
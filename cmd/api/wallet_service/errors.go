@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/Dev317/golang_wallet/chain"
+	"github.com/Dev317/golang_wallet/respond"
+)
+
+// Error codes of the wallet API, on top of the shared ones in package respond.
+const (
+	codeAccountNotFound         = "account_not_found"
+	codeTransactionNotFound     = "transaction_not_found"
+	codeContactNotFound         = "contact_not_found"
+	codePolicyNotFound          = "policy_not_found"
+	codeWebhookNotFound         = "webhook_not_found"
+	codeWebhookDeliveryNotFound = "webhook_delivery_not_found"
+	codeApprovalRuleNotFound    = "approval_rule_not_found"
+	codeApprovalRequestNotFound = "approval_request_not_found"
+
+	codeDuplicateEmail        = "duplicate_email"
+	codeDuplicateContact      = "duplicate_contact"
+	codeDuplicateApprovalRule = "duplicate_approval_rule"
+
+	codeInvalidCredentials    = "invalid_credentials"
+	codeInvalidToken          = "invalid_token"
+	codeTokenExpired          = "token_expired"
+	codeInvalidWalletPassword = "invalid_wallet_password"
+
+	codeAccountKeyNotImported = "account_key_not_imported"
+
+	codeUnsupportedChain         = "unsupported_chain"
+	codeUnknownToken             = "unknown_token"
+	codePolicyViolation          = "policy_violation"
+	codeContactCoolingOff        = "contact_cooling_off"
+	codeInvalidTransactionStatus = "invalid_transaction_status"
+	codeNotApprover              = "not_an_approver"
+	codeAlreadyDecided           = "already_decided"
+	codeApprovalExpired          = "approval_expired"
+
+	codeIdempotencyKeyReused = "idempotency_key_reused"
+	codeRequestInProgress    = "request_in_progress"
+)
+
+// knownErrors maps the wallet's sentinel errors to the status, code and fixed
+// message they are reported with. Errors wrapping them may carry internal
+// detail, so that is only logged.
+var knownErrors = []struct {
+	err     error
+	status  int
+	code    string
+	message string
+}{
+	{errInvalidWalletPassword, http.StatusUnauthorized, codeInvalidWalletPassword, "Invalid wallet password"},
+	{errAccountKeyNotImported, http.StatusConflict, codeAccountKeyNotImported, "Account key is held by the client and cannot sign on the server"},
+	{errInvalidDestination, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid destination"},
+	{errInvalidAmount, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid amount"},
+	{errFeeCapBelowTip, http.StatusBadRequest, respond.CodeInvalidRequest, "max_fee_per_gas must not be lower than max_priority_fee_per_gas"},
+	{errUnknownToken, http.StatusBadRequest, codeUnknownToken, "Unknown token"},
+	{errContactNotFound, http.StatusNotFound, codeContactNotFound, "Contact not found"},
+	{errContactCoolingOff, http.StatusForbidden, codeContactCoolingOff, "Contact is still in its cooling-off period"},
+	{errNotReplaceable, http.StatusConflict, codeInvalidTransactionStatus, "Only pending broadcast transactions can be replaced"},
+	{errAlreadyReplacing, http.StatusConflict, codeInvalidTransactionStatus, "Transaction is already being replaced"},
+	{errReplacementUnderpriced, http.StatusConflict, respond.CodeReplacementUnderpriced, "Fee is below the minimum replacement bump"},
+	{errInvalidTransition, http.StatusConflict, codeInvalidTransactionStatus, "Transaction status changed concurrently"},
+	{errInvalidPagination, http.StatusBadRequest, respond.CodeInvalidRequest, "limit must be a positive integer and offset a non-negative one"},
+	{errNotApprover, http.StatusForbidden, codeNotApprover, "Caller is not an approver"},
+	{errNotPendingApprove, http.StatusConflict, codeInvalidTransactionStatus, "Transaction is not awaiting approval"},
+	{errApprovalExpired, http.StatusConflict, codeApprovalExpired, "Approval request has expired"},
+	{errAlreadyDecided, http.StatusConflict, codeAlreadyDecided, "Caller has already decided"},
+	{errNoRuleDeletion, http.StatusNotFound, codeApprovalRequestNotFound, "No deletion was requested for this rule"},
+	{chain.ErrUnknownChain, http.StatusBadRequest, codeUnsupportedChain, "Unsupported chain"},
+	{chain.ErrNoEndpoints, http.StatusServiceUnavailable, respond.CodeUnavailable, "Chain is temporarily unavailable"},
+}
+
+// writeError reports err to the caller: as field errors for a
+// *ValidationError, with its own code for the wallet's known errors, and
+// otherwise as mapped by respond.FromError.
+func writeError(w http.ResponseWriter, err error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		respond.Invalid(w, invalid.Fields)
+		return
+	}
+
+	var violation *PolicyViolation
+	if errors.As(err, &violation) {
+		respond.WriteError(w, http.StatusForbidden, codePolicyViolation, violation.Error())
+		return
+	}
+
+	for _, known := range knownErrors {
+		if errors.Is(err, known.err) {
+			logger.Info("Request rejected",
+				slog.String("code", known.code),
+				slog.Any("error", err),
+			)
+			respond.WriteError(w, known.status, known.code, known.message)
+			return
+		}
+	}
+	respond.FromError(w, err)
+}
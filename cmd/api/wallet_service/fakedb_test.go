@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeDB stands in for Postgres behind db.Queries. Each query the code under
+// test runs is answered by the handler registered under its sqlc name, which
+// returns the values of the resulting row in column order.
+type fakeDB struct {
+	handlers map[string]func(args []any) ([]any, error)
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{handlers: map[string]func(args []any) ([]any, error){}}
+}
+
+// on registers handle for the query named name. Handlers may run
+// concurrently and guard any state they share.
+func (f *fakeDB) on(name string, handle func(args []any) ([]any, error)) {
+	f.handlers[name] = handle
+}
+
+var queryName = regexp.MustCompile(`-- name: (\w+)`)
+
+func (f *fakeDB) run(sql string, args []any) ([]any, error) {
+	match := queryName.FindStringSubmatch(sql)
+	if match == nil {
+		return nil, fmt.Errorf("fakeDB: unnamed query %q", sql)
+	}
+	handle, ok := f.handlers[match[1]]
+	if !ok {
+		return nil, fmt.Errorf("fakeDB: unexpected query %s", match[1])
+	}
+	return handle(args)
+}
+
+func (f *fakeDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	_, err := f.run(sql, args)
+	return pgconn.NewCommandTag("OK"), err
+}
+
+func (f *fakeDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, fmt.Errorf("fakeDB: Query is not supported")
+}
+
+func (f *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	values, err := f.run(sql, args)
+	return fakeRow{values: values, err: err}
+}
+
+type fakeRow struct {
+	values []any
+	err    error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if len(dest) != len(r.values) {
+		return fmt.Errorf("fakeDB: scanning %d columns into %d values", len(r.values), len(dest))
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r.values[i]))
+	}
+	return nil
+}
+
+// columns returns the fields of a generated model in column order, the order
+// sqlc scans a row of its table in.
+func columns(model any) []any {
+	v := reflect.ValueOf(model)
+	values := make([]any, v.NumField())
+	for i := range values {
+		values[i] = v.Field(i).Interface()
+	}
+	return values
+}
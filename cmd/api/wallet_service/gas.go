@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+var errFeeCapBelowTip = errors.New("max_fee_per_gas must not be lower than max_priority_fee_per_gas")
+
+// FeeOptions carries optional caller-supplied EIP-1559 fee caps in wei. Nil
+// fields are filled from the node's suggestions.
+type FeeOptions struct {
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// txFees are the fee parameters a transaction was built with. GasPrice is set
+// for legacy transactions; the fee caps for dynamic-fee transactions.
+type txFees struct {
+	Type                 uint8
+	GasPrice             *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+}
+
+// suggestFees prices a transaction for the chain behind client. Chains whose
+// latest header carries a base fee get a dynamic-fee transaction with
+// maxFee = 2*baseFee + tip, which stays valid through several full blocks;
+// pre-London chains fall back to a legacy gas price.
+func suggestFees(ctx context.Context, client *ethclient.Client, opts FeeOptions) (txFees, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return txFees{}, err
+	}
+
+	if header.BaseFee == nil {
+		// Without a fee market the fee cap is the price the caller will pay.
+		gasPrice := opts.MaxFeePerGas
+		if gasPrice == nil {
+			gasPrice, err = client.SuggestGasPrice(ctx)
+			if err != nil {
+				return txFees{}, err
+			}
+		}
+		return txFees{Type: types.LegacyTxType, GasPrice: gasPrice}, nil
+	}
+
+	tip := opts.MaxPriorityFeePerGas
+	if tip == nil {
+		tip, err = client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return txFees{}, err
+		}
+	}
+
+	maxFee := opts.MaxFeePerGas
+	if maxFee == nil {
+		maxFee = new(big.Int).Mul(header.BaseFee, big.NewInt(2))
+		maxFee.Add(maxFee, tip)
+	}
+
+	if maxFee.Cmp(tip) < 0 {
+		return txFees{}, errFeeCapBelowTip
+	}
+
+	return txFees{
+		Type:                 types.DynamicFeeTxType,
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: tip,
+	}, nil
+}
+
+// newUnsignedTx builds the transaction for fees along with the signer that
+// must be used for it.
+func newUnsignedTx(chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gasLimit uint64, data []byte, fees txFees) (*types.Transaction, types.Signer) {
+	if fees.Type == types.LegacyTxType {
+		tx := types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &to,
+			Value:    value,
+			Gas:      gasLimit,
+			GasPrice: fees.GasPrice,
+			Data:     data,
+		})
+		return tx, types.NewEIP155Signer(chainID)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Value:     value,
+		Gas:       gasLimit,
+		GasTipCap: fees.MaxPriorityFeePerGas,
+		GasFeeCap: fees.MaxFeePerGas,
+		Data:      data,
+	})
+	return tx, types.NewLondonSigner(chainID)
+}
+
+// replacementBumpPercent is the minimum fee increase nodes require before
+// they accept a transaction replacing a pending one with the same nonce
+// (geth's default txpool price bump).
+const replacementBumpPercent = 10
+
+var errReplacementUnderpriced = errors.New("fee is below the minimum replacement bump")
+
+// bumpFee returns v raised by replacementBumpPercent, rounded up.
+func bumpFee(v *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(v, big.NewInt(100+replacementBumpPercent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Quo(bumped, big.NewInt(100))
+}
+
+// applyReplacementFloor raises fees to the minimum a replacement for original
+// must pay. Suggested fees are raised silently; fees the caller set explicitly
+// are rejected if they fall short.
+func applyReplacementFloor(fees txFees, original db.Transaction, opts FeeOptions) (txFees, error) {
+	var minTip, minCap *big.Int
+	if original.TxType == types.LegacyTxType {
+		// Nodes treat a legacy gas price as both the tip and the fee cap.
+		minTip = bumpFee(store.NumericToBig(original.GasPrice))
+		minCap = minTip
+	} else {
+		minTip = bumpFee(store.NumericToBig(original.MaxPriorityFeePerGas))
+		minCap = bumpFee(store.NumericToBig(original.MaxFeePerGas))
+	}
+
+	raise := func(fee *big.Int, floor *big.Int, explicit bool) (*big.Int, error) {
+		if fee.Cmp(floor) >= 0 {
+			return fee, nil
+		}
+		if explicit {
+			return nil, fmt.Errorf("%w: need at least %s wei", errReplacementUnderpriced, floor)
+		}
+		return floor, nil
+	}
+
+	var err error
+	if fees.Type == types.LegacyTxType {
+		fees.GasPrice, err = raise(fees.GasPrice, minCap, opts.MaxFeePerGas != nil)
+		return fees, err
+	}
+
+	fees.MaxPriorityFeePerGas, err = raise(fees.MaxPriorityFeePerGas, minTip, opts.MaxPriorityFeePerGas != nil)
+	if err != nil {
+		return fees, err
+	}
+	fees.MaxFeePerGas, err = raise(fees.MaxFeePerGas, minCap, opts.MaxFeePerGas != nil)
+	if err != nil {
+		return fees, err
+	}
+	if fees.MaxFeePerGas.Cmp(fees.MaxPriorityFeePerGas) < 0 {
+		fees.MaxFeePerGas = fees.MaxPriorityFeePerGas
+	}
+	return fees, nil
+}
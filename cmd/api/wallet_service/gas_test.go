@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpFee(t *testing.T) {
+	tests := []struct {
+		fee  int64
+		want int64
+	}{
+		{0, 0},
+		{1, 2},
+		{10, 11},
+		{100, 110},
+		{101, 112},
+		{1_000_000_000, 1_100_000_000},
+		{1_000_000_001, 1_100_000_002},
+	}
+	for _, tt := range tests {
+		got := bumpFee(big.NewInt(tt.fee))
+		if got.Int64() != tt.want {
+			t.Errorf("bumpFee(%d) = %s, want %d", tt.fee, got, tt.want)
+		}
+	}
+}
+
+func TestApplyReplacementFloorLegacy(t *testing.T) {
+	original := db.Transaction{
+		TxType:   types.LegacyTxType,
+		GasPrice: store.BigToNumeric(big.NewInt(100)),
+	}
+
+	fees := txFees{Type: types.LegacyTxType, GasPrice: big.NewInt(90)}
+	got, err := applyReplacementFloor(fees, original, FeeOptions{})
+	if err != nil {
+		t.Fatalf("suggested fee: %v", err)
+	}
+	if got.GasPrice.Int64() != 110 {
+		t.Errorf("suggested gas price = %s, want 110", got.GasPrice)
+	}
+
+	fees = txFees{Type: types.LegacyTxType, GasPrice: big.NewInt(120)}
+	got, err = applyReplacementFloor(fees, original, FeeOptions{})
+	if err != nil {
+		t.Fatalf("fee above floor: %v", err)
+	}
+	if got.GasPrice.Int64() != 120 {
+		t.Errorf("gas price above floor = %s, want 120", got.GasPrice)
+	}
+
+	fees = txFees{Type: types.LegacyTxType, GasPrice: big.NewInt(105)}
+	_, err = applyReplacementFloor(fees, original, FeeOptions{MaxFeePerGas: big.NewInt(105)})
+	if !errors.Is(err, errReplacementUnderpriced) {
+		t.Errorf("explicit fee below floor: error = %v, want errReplacementUnderpriced", err)
+	}
+}
+
+func TestApplyReplacementFloorDynamic(t *testing.T) {
+	original := db.Transaction{
+		TxType:               types.DynamicFeeTxType,
+		MaxFeePerGas:         store.BigToNumeric(big.NewInt(200)),
+		MaxPriorityFeePerGas: store.BigToNumeric(big.NewInt(10)),
+	}
+
+	fees := txFees{
+		Type:                 types.DynamicFeeTxType,
+		MaxFeePerGas:         big.NewInt(150),
+		MaxPriorityFeePerGas: big.NewInt(5),
+	}
+	got, err := applyReplacementFloor(fees, original, FeeOptions{})
+	if err != nil {
+		t.Fatalf("suggested fees: %v", err)
+	}
+	if got.MaxPriorityFeePerGas.Int64() != 11 || got.MaxFeePerGas.Int64() != 220 {
+		t.Errorf("suggested fees = %s/%s, want 220/11", got.MaxFeePerGas, got.MaxPriorityFeePerGas)
+	}
+
+	// An explicit tip above the floor is kept; the suggested cap is raised.
+	fees = txFees{
+		Type:                 types.DynamicFeeTxType,
+		MaxFeePerGas:         big.NewInt(150),
+		MaxPriorityFeePerGas: big.NewInt(300),
+	}
+	got, err = applyReplacementFloor(fees, original, FeeOptions{MaxPriorityFeePerGas: big.NewInt(300)})
+	if err != nil {
+		t.Fatalf("explicit tip: %v", err)
+	}
+	if got.MaxPriorityFeePerGas.Int64() != 300 {
+		t.Errorf("tip = %s, want 300", got.MaxPriorityFeePerGas)
+	}
+	if got.MaxFeePerGas.Int64() != 300 {
+		t.Errorf("fee cap = %s, want it raised to the tip, 300", got.MaxFeePerGas)
+	}
+
+	fees = txFees{
+		Type:                 types.DynamicFeeTxType,
+		MaxFeePerGas:         big.NewInt(210),
+		MaxPriorityFeePerGas: big.NewInt(11),
+	}
+	_, err = applyReplacementFloor(fees, original, FeeOptions{MaxFeePerGas: big.NewInt(210)})
+	if !errors.Is(err, errReplacementUnderpriced) {
+		t.Errorf("explicit cap below floor: error = %v, want errReplacementUnderpriced", err)
+	}
+
+	fees = txFees{
+		Type:                 types.DynamicFeeTxType,
+		MaxFeePerGas:         big.NewInt(300),
+		MaxPriorityFeePerGas: big.NewInt(10),
+	}
+	_, err = applyReplacementFloor(fees, original, FeeOptions{MaxPriorityFeePerGas: big.NewInt(10)})
+	if !errors.Is(err, errReplacementUnderpriced) {
+		t.Errorf("explicit tip below floor: error = %v, want errReplacementUnderpriced", err)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"math/big"
+	"os"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v5"
+	"github.com/tyler-smith/go-bip39"
+)
+
+var errInvalidDerivedKey = errors.New("derived key is invalid for this index")
+
+// newMnemonic returns a fresh 24-word BIP-39 mnemonic.
+func newMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// accountDerivationPath returns m/44'/60'/0'/0/index.
+func accountDerivationPath(index uint32) accounts.DerivationPath {
+	path := make(accounts.DerivationPath, len(accounts.DefaultBaseDerivationPath))
+	copy(path, accounts.DefaultBaseDerivationPath)
+	path[len(path)-1] = index
+	return path
+}
+
+// deriveAccountKey derives the private key at m/44'/60'/0'/0/index from a
+// BIP-39 mnemonic using BIP-32 private child derivation.
+func deriveAccountKey(mnemonic string, index uint32) (*ecdsa.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	curveOrder := crypto.S256().Params().N
+	for _, n := range accountDerivationPath(index) {
+		var data []byte
+		if n >= 0x80000000 {
+			data = append([]byte{0x00}, key...)
+		} else {
+			privateKey, err := crypto.ToECDSA(key)
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&privateKey.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, n)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curveOrder) >= 0 {
+			return nil, errInvalidDerivedKey
+		}
+		child := tweak.Add(tweak, new(big.Int).SetBytes(key))
+		child.Mod(child, curveOrder)
+		if child.Sign() == 0 {
+			return nil, errInvalidDerivedKey
+		}
+
+		key = child.FillBytes(make([]byte, 32))
+		chainCode = sum[32:]
+	}
+	return crypto.ToECDSA(key)
+}
+
+// ensureWalletSeed returns the user's mnemonic, creating and storing a new one
+// under the wallet password if the user has none yet. created reports whether
+// the mnemonic was generated by this call.
+func ensureWalletSeed(ctx context.Context, q *db.Queries, userID int64, password string) (mnemonic string, created bool, err error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	seed, err := q.GetWalletSeedByUserId(ctx, userID)
+	if err == nil {
+		secret, err := decryptSecret(seed.Crypto, password)
+		if err != nil {
+			return "", false, err
+		}
+		return string(secret), false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", false, err
+	}
+
+	mnemonic, err = newMnemonic()
+	if err != nil {
+		logger.Error("Failed to generate mnemonic",
+			slog.Any("error", err),
+		)
+		return "", false, err
+	}
+
+	encrypted, err := encryptSecret([]byte(mnemonic), password)
+	if err != nil {
+		return "", false, err
+	}
+
+	_, err = q.CreateWalletSeed(ctx, db.CreateWalletSeedParams{
+		UserID: userID,
+		Crypto: encrypted,
+	})
+	if err != nil {
+		return "", false, err
+	}
+	logger.Info("Wallet seed created", slog.Int64("user_id", userID))
+	return mnemonic, true, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Known addresses at m/44'/60'/0'/0/i, as produced by MetaMask, Ledger and
+// Hardhat for the same mnemonics.
+func TestDeriveAccountKey(t *testing.T) {
+	abandon := strings.Repeat("abandon ", 11) + "about"
+	hardhat := "test test test test test test test test test test test junk"
+
+	tests := []struct {
+		mnemonic string
+		index    uint32
+		address  string
+		key      string
+	}{
+		{abandon, 0, "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", ""},
+		{abandon, 1, "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0", ""},
+		{abandon, 2, "0xb6716976A3ebe8D39aCEB04372f22Ff8e6802D7A", ""},
+		{hardhat, 0, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"},
+		{hardhat, 1, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"},
+	}
+
+	for _, tt := range tests {
+		privateKey, err := deriveAccountKey(tt.mnemonic, tt.index)
+		if err != nil {
+			t.Fatalf("deriveAccountKey(%d): %v", tt.index, err)
+		}
+		if got := crypto.PubkeyToAddress(privateKey.PublicKey).Hex(); got != tt.address {
+			t.Errorf("address at index %d = %s, want %s", tt.index, got, tt.address)
+		}
+		if tt.key != "" {
+			if got := fmt.Sprintf("%x", crypto.FromECDSA(privateKey)); got != tt.key {
+				t.Errorf("key at index %d = %s, want %s", tt.index, got, tt.key)
+			}
+		}
+	}
+}
+
+func TestDeriveAccountKeyRejectsBadMnemonic(t *testing.T) {
+	_, err := deriveAccountKey(strings.TrimSpace(strings.Repeat("abandon ", 12)), 0)
+	if err == nil {
+		t.Fatal("expected an error for a mnemonic with a bad checksum")
+	}
+}
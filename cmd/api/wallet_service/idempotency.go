@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotentRequestMaxBytes = 1 << 20
+
+	// idempotencyRetention is how long finished keys are kept for replay.
+	idempotencyRetention     = 24 * time.Hour
+	idempotencyPruneInterval = time.Hour
+)
+
+// responseRecorder passes a response through while keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// idempotent lets callers retry next safely by sending an Idempotency-Key
+// header. The first request with a key runs normally and its response is
+// stored; a retry with the same key and body gets the stored response back
+// instead of running again, and a retry with a different body is rejected.
+// Every response is stored, errors included, because a failed request may
+// still have reached the chain; a new attempt needs a new key. A key whose
+// request never finished, e.g. because the process died, is not run again:
+// the request may have sent a transaction, so retries get 409 until the
+// caller checks its transactions and uses a new key. Finished keys are kept
+// for idempotencyRetention.
+func (server *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, idempotentRequestMaxBytes))
+		if err != nil {
+			respond.InvalidBody(w, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := requestFingerprint(r.Method, r.URL.Path, body)
+		userID := callerID(r.Context())
+
+		record, err := server.q.CreateIdempotencyKey(r.Context(), db.CreateIdempotencyKeyParams{
+			UserID:         userID,
+			IdempotencyKey: key,
+			RequestHash:    requestHash,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			server.replayIdempotent(w, r, userID, key, requestHash)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next(rec, r)
+
+		// Store the outcome even if the caller has gone away in the meantime.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = server.q.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+			ID:                  record.ID,
+			ResponseStatus:      pgtype.Int4{Int32: int32(rec.status), Valid: true},
+			ResponseContentType: pgtype.Text{String: rec.Header().Get("Content-Type"), Valid: true},
+			ResponseBody:        rec.body.Bytes(),
+			CompletedAt:         timestamp(time.Now().UTC()),
+		})
+		if err != nil {
+			logger.Error("Failed to store idempotent response",
+				slog.Int64("idempotency_key_id", record.ID),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, path and body, so a
+// key reused for a different request can be told apart from a retry.
+func requestFingerprint(method, path string, body []byte) string {
+	fingerprint := sha256.Sum256(append([]byte(method+" "+path+"\n"), body...))
+	return hex.EncodeToString(fingerprint[:])
+}
+
+// replayIdempotent answers a request whose key has been seen before.
+func (server *Server) replayIdempotent(w http.ResponseWriter, r *http.Request, userID int64, key string, requestHash string) {
+	record, err := server.q.GetIdempotencyKey(r.Context(), db.GetIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		respond.WriteError(w, http.StatusConflict, codeIdempotencyKeyReused, "Idempotency-Key was already used with a different request")
+		return
+	}
+	if !record.ResponseStatus.Valid {
+		respond.WriteError(w, http.StatusConflict, codeRequestInProgress, "A request with this Idempotency-Key is still in progress")
+		return
+	}
+
+	if record.ResponseContentType.String != "" {
+		w.Header().Set("Content-Type", record.ResponseContentType.String)
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(int(record.ResponseStatus.Int32))
+	w.Write(record.ResponseBody)
+}
+
+// PruneIdempotencyKeys periodically deletes keys finished more than
+// idempotencyRetention ago. Keys still in progress are kept.
+func (server *Server) PruneIdempotencyKeys() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ticker := time.NewTicker(idempotencyPruneInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		before := timestamp(time.Now().UTC().Add(-idempotencyRetention))
+		err := server.q.DeleteExpiredIdempotencyKeys(context.Background(), before)
+		if err != nil {
+			logger.Error("Failed to prune idempotency keys",
+				slog.Any("error", err),
+			)
+		}
+	}
+}
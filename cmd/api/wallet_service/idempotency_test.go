@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// idempotencyStore serves the idempotency key queries from memory with the
+// same conflict behavior as the table's unique index.
+func idempotencyStore(fake *fakeDB) map[string]*db.IdempotencyKey {
+	var mu sync.Mutex
+	keys := map[string]*db.IdempotencyKey{}
+	fake.on("CreateIdempotencyKey", func(args []any) ([]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		key := args[1].(string)
+		if _, ok := keys[key]; ok {
+			return nil, pgx.ErrNoRows
+		}
+		keys[key] = &db.IdempotencyKey{
+			ID:             int64(len(keys) + 1),
+			UserID:         args[0].(int64),
+			IdempotencyKey: key,
+			RequestHash:    args[2].(string),
+		}
+		return columns(*keys[key]), nil
+	})
+	fake.on("GetIdempotencyKey", func(args []any) ([]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		record, ok := keys[args[1].(string)]
+		if !ok {
+			return nil, pgx.ErrNoRows
+		}
+		return columns(*record), nil
+	})
+	fake.on("CompleteIdempotencyKey", func(args []any) ([]any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, record := range keys {
+			if record.ID == args[0].(int64) {
+				record.ResponseStatus = args[1].(pgtype.Int4)
+				record.ResponseContentType = args[2].(pgtype.Text)
+				record.ResponseBody = args[3].([]byte)
+				record.CompletedAt = args[4].(pgtype.Timestamp)
+			}
+		}
+		return nil, nil
+	})
+	return keys
+}
+
+func TestIdempotent(t *testing.T) {
+	fake := newFakeDB()
+	keys := idempotencyStore(fake)
+	server := &Server{q: db.New(fake)}
+
+	// An earlier request with this key never finished.
+	keys["stuck"] = &db.IdempotencyKey{
+		ID:             100,
+		UserID:         7,
+		IdempotencyKey: "stuck",
+		RequestHash:    requestFingerprint(http.MethodPost, "/api/v1/account/create_transaction", []byte(`{"value":"1"}`)),
+	}
+
+	var calls int
+	handler := server.idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		respond.JSON(w, http.StatusCreated, map[string]int{"call": calls})
+	})
+	send := func(key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/account/create_transaction", strings.NewReader(body))
+		request = request.WithContext(context.WithValue(request.Context(), userIDContextKey, int64(7)))
+		if key != "" {
+			request.Header.Set(idempotencyKeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		return recorder
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		body     string
+		status   int
+		code     string
+		calls    int
+		replayed bool
+	}{
+		{"first request", "key-1", `{"value":"1"}`, http.StatusCreated, "", 1, false},
+		{"retry", "key-1", `{"value":"1"}`, http.StatusCreated, "", 1, true},
+		{"different body", "key-1", `{"value":"2"}`, http.StatusConflict, codeIdempotencyKeyReused, 1, false},
+		{"no key", "", `{"value":"1"}`, http.StatusCreated, "", 2, false},
+		{"new key", "key-2", `{"value":"1"}`, http.StatusCreated, "", 3, false},
+		{"unfinished request", "stuck", `{"value":"1"}`, http.StatusConflict, codeRequestInProgress, 3, false},
+	}
+	for _, tt := range tests {
+		recorder := send(tt.key, tt.body)
+		if recorder.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, recorder.Code, tt.status)
+		}
+		if tt.code != "" {
+			if code := errorCode(t, recorder); code != tt.code {
+				t.Errorf("%s: code = %q, want %q", tt.name, code, tt.code)
+			}
+		}
+		if calls != tt.calls {
+			t.Errorf("%s: handler ran %d times in total, want %d", tt.name, calls, tt.calls)
+		}
+		if replayed := recorder.Header().Get(idempotentReplayedHeader) == "true"; replayed != tt.replayed {
+			t.Errorf("%s: replayed = %v, want %v", tt.name, replayed, tt.replayed)
+		}
+	}
+
+	if got := send("key-1", `{"value":"1"}`).Body.String(); !strings.Contains(got, `"call":1`) {
+		t.Errorf("replayed body = %s, want the first response", got)
+	}
+	if keys["stuck"].ResponseStatus.Valid {
+		t.Error("unfinished request was completed by a retry")
+	}
+}
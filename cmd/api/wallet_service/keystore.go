@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	errInvalidWalletPassword = errors.New("invalid wallet password")
+	errKeystoreMismatch      = errors.New("keystore does not match account address")
+	errAccountKeyNotImported = errors.New("account key is held by the client")
+)
+
+// encryptSecret seals a secret under the user's wallet password using
+// go-ethereum's scrypt + AES-128-CTR keystore format.
+func encryptSecret(secret []byte, password string) ([]byte, error) {
+	cryptoJSON, err := keystore.EncryptDataV3(
+		secret,
+		[]byte(password),
+		keystore.StandardScryptN,
+		keystore.StandardScryptP,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cryptoJSON)
+}
+
+func decryptSecret(data []byte, password string) ([]byte, error) {
+	var cryptoJSON keystore.CryptoJSON
+	err := json.Unmarshal(data, &cryptoJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := keystore.DecryptDataV3(cryptoJSON, password)
+	if err != nil {
+		if errors.Is(err, keystore.ErrDecrypt) {
+			return nil, errInvalidWalletPassword
+		}
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (server *Server) verifyWalletPassword(ctx context.Context, userID int64, password string) error {
+	user, err := server.q.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.WalletHashPassword), []byte(password))
+	if err != nil {
+		return errInvalidWalletPassword
+	}
+	return nil
+}
+
+// loadAccountKey returns the signing key for accountID and checks that it
+// still derives the address recorded on the account. HD accounts are derived
+// from the user's seed; other accounts use their individually encrypted
+// keystore entry. Accounts whose key stayed with the client have neither and
+// cannot sign here.
+func (server *Server) loadAccountKey(ctx context.Context, accountID int64, password string) (*ecdsa.PrivateKey, error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	account, err := server.q.GetAccountById(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateKey *ecdsa.PrivateKey
+	if account.DerivationIndex.Valid {
+		seed, err := server.q.GetWalletSeedByUserId(ctx, account.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		mnemonic, err := decryptSecret(seed.Crypto, password)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err = deriveAccountKey(string(mnemonic), uint32(account.DerivationIndex.Int32))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ks, err := server.q.GetKeystoreByAccountId(ctx, accountID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errAccountKeyNotImported
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		keyBytes, err := decryptSecret(ks.Crypto, password)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey, err = crypto.ToECDSA(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if crypto.PubkeyToAddress(privateKey.PublicKey).Hex() != account.Address {
+		logger.Error("Keystore address mismatch",
+			slog.Int64("account_id", accountID),
+			slog.String("address", account.Address),
+		)
+		return nil, errKeystoreMismatch
+	}
+	return privateKey, nil
+}
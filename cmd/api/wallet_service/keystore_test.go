@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+)
+
+func TestEncryptSecret(t *testing.T) {
+	secret := []byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+
+	sealed, err := encryptSecret(secret, "wallet-password")
+	if err != nil {
+		t.Fatalf("encryptSecret: %v", err)
+	}
+	if bytes.Contains(sealed, secret) {
+		t.Fatal("sealed secret contains the plaintext")
+	}
+
+	var cryptoJSON keystore.CryptoJSON
+	err = json.Unmarshal(sealed, &cryptoJSON)
+	if err != nil {
+		t.Fatalf("sealed secret is not keystore JSON: %v", err)
+	}
+	n, _ := cryptoJSON.KDFParams["n"].(float64)
+	p, _ := cryptoJSON.KDFParams["p"].(float64)
+	if cryptoJSON.KDF != "scrypt" || int(n) != keystore.StandardScryptN || int(p) != keystore.StandardScryptP {
+		t.Errorf("KDF = %s n=%v p=%v, want scrypt with the standard parameters", cryptoJSON.KDF, n, p)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		password string
+		want     error
+	}{
+		{"correct password", sealed, "wallet-password", nil},
+		{"wrong password", sealed, "wrong-password", errInvalidWalletPassword},
+	}
+	for _, tt := range tests {
+		got, err := decryptSecret(tt.data, tt.password)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if err == nil && !bytes.Equal(got, secret) {
+			t.Errorf("%s: decrypted %q, want %q", tt.name, got, secret)
+		}
+	}
+
+	_, err = decryptSecret([]byte("not json"), "wallet-password")
+	if err == nil || errors.Is(err, errInvalidWalletPassword) {
+		t.Errorf("malformed keystore: error = %v, want a decoding error", err)
+	}
+}
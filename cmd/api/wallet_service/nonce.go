@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// reserveNonce allocates a nonce for a send from address. The account's nonce
+// row is locked for the allocation so concurrent sends never share a nonce.
+// Nonces released by failed sends are handed out first, so a failure does not
+// leave a gap that blocks every later transaction.
+func (server *Server) reserveNonce(ctx context.Context, client *ethclient.Client, chainID int32, address string) (uint64, error) {
+	var nonce int64
+	err := server.store.ExecTx(ctx, func(q *db.Queries) error {
+		key := db.LockAccountNonceParams{ChainID: chainID, Address: address}
+
+		row, err := q.LockAccountNonce(ctx, key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// First send from this account: start from the chain's view.
+			pending, err := client.PendingNonceAt(ctx, common.HexToAddress(address))
+			if err != nil {
+				return err
+			}
+			err = q.CreateAccountNonce(ctx, db.CreateAccountNonceParams{
+				ChainID:   chainID,
+				Address:   address,
+				NextNonce: int64(pending),
+			})
+			if err != nil {
+				return err
+			}
+			row, err = q.LockAccountNonce(ctx, key)
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		released, err := q.TakeReleasedNonce(ctx, db.TakeReleasedNonceParams{ChainID: chainID, Address: address})
+		if err == nil {
+			nonce = released
+			return nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		nonce = row.NextNonce
+		return q.SetAccountNextNonce(ctx, db.SetAccountNextNonceParams{
+			ChainID:   chainID,
+			Address:   address,
+			NextNonce: row.NextNonce + 1,
+		})
+	})
+	return uint64(nonce), err
+}
+
+// releaseNonce returns a reserved nonce whose transaction never reached the
+// network so the next send reuses it.
+func (server *Server) releaseNonce(ctx context.Context, chainID int32, address string, nonce uint64) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	err := server.q.ReleaseNonce(ctx, db.ReleaseNonceParams{
+		ChainID: chainID,
+		Address: address,
+		Nonce:   int64(nonce),
+	})
+	if err != nil {
+		logger.Error("Failed to release nonce",
+			slog.String("address", address),
+			slog.Uint64("nonce", nonce),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// alreadyKnown reports whether a send failed because the node already has
+// this very transaction in its pool, i.e. it is on the network after all.
+func alreadyKnown(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "already known")
+}
+
+// nonceTooLow reports whether another transaction took the nonce first.
+func nonceTooLow(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "nonce too low")
+}
+
+// sendRejected reports whether the node answered a send with an error, so
+// the transaction definitely did not enter its pool. Transport failures and
+// timeouts are ambiguous: the transaction may have propagated regardless.
+func sendRejected(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr)
+}
+
+// reconcileNonces brings every tracked account in line with its chain's
+// pending nonce. Nonces the chain has moved past are no longer ours to hand
+// out; nonces we reserved that the node has never seen, and that no live
+// transaction holds, are released for reuse.
+func (server *Server) reconcileNonces(ctx context.Context) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	rows, err := server.q.ListAccountNonces(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		client, err := server.clients.Client(strconv.Itoa(int(row.ChainID)))
+		if err != nil {
+			logger.Warn("Skipping nonce reconciliation",
+				slog.String("address", row.Address),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		err = server.syncNonce(ctx, client, row.ChainID, row.Address, true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncNonce moves the account's next nonce up to the chain's pending nonce
+// and drops released nonces the chain has used. With releaseUnused, reserved
+// nonces the chain has not seen and no live transaction holds are released as
+// well; that is only safe while no sends are in progress, i.e. at startup,
+// since a nonce reserved for a send that is not yet signed looks the same.
+func (server *Server) syncNonce(ctx context.Context, client *ethclient.Client, chainID int32, address string, releaseUnused bool) error {
+	pending, err := client.PendingNonceAt(ctx, common.HexToAddress(address))
+	if err != nil {
+		return err
+	}
+
+	return server.store.ExecTx(ctx, func(q *db.Queries) error {
+		locked, err := q.LockAccountNonce(ctx, db.LockAccountNonceParams{ChainID: chainID, Address: address})
+		if err != nil {
+			return err
+		}
+
+		err = q.PruneReleasedNonces(ctx, db.PruneReleasedNoncesParams{
+			ChainID: chainID,
+			Address: address,
+			Nonce:   int64(pending),
+		})
+		if err != nil {
+			return err
+		}
+
+		if int64(pending) >= locked.NextNonce {
+			return q.SetAccountNextNonce(ctx, db.SetAccountNextNonceParams{
+				ChainID:   chainID,
+				Address:   address,
+				NextNonce: int64(pending),
+			})
+		}
+		if !releaseUnused {
+			return nil
+		}
+
+		inFlight, err := q.ListInFlightNonces(ctx, db.ListInFlightNoncesParams{
+			ChainID:     chainID,
+			FromAddress: address,
+			Nonce:       pgtype.Int8{Int64: int64(pending), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		held := make(map[int64]bool, len(inFlight))
+		for _, nonce := range inFlight {
+			held[nonce.Int64] = true
+		}
+
+		for nonce := int64(pending); nonce < locked.NextNonce; nonce++ {
+			if held[nonce] {
+				continue
+			}
+			err := q.ReleaseNonce(ctx, db.ReleaseNonceParams{
+				ChainID: chainID,
+				Address: address,
+				Nonce:   nonce,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
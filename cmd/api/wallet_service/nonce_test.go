@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+)
+
+// rpcError is an error answered by the node, as rpc.Error.
+type rpcError struct {
+	code    int
+	message string
+}
+
+func (e rpcError) Error() string  { return e.message }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestSendErrorClassification(t *testing.T) {
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")}
+
+	tests := []struct {
+		name         string
+		err          error
+		alreadyKnown bool
+		nonceTooLow  bool
+		rejected     bool
+	}{
+		{"already known", rpcError{-32000, "already known"}, true, false, true},
+		{"nonce too low", rpcError{-32000, "nonce too low: next nonce 5, tx nonce 4"}, false, true, true},
+		{"underpriced", rpcError{-32000, "replacement transaction underpriced"}, false, false, true},
+		{"wrapped rejection", fmt.Errorf("send: %w", rpcError{-32000, "insufficient funds for gas * price + value"}), false, false, true},
+		{"timeout", timeout, false, false, false},
+		{"cancelled", context.DeadlineExceeded, false, false, false},
+	}
+	for _, tt := range tests {
+		if got := alreadyKnown(tt.err); got != tt.alreadyKnown {
+			t.Errorf("%s: alreadyKnown = %v, want %v", tt.name, got, tt.alreadyKnown)
+		}
+		if got := nonceTooLow(tt.err); got != tt.nonceTooLow {
+			t.Errorf("%s: nonceTooLow = %v, want %v", tt.name, got, tt.nonceTooLow)
+		}
+		if got := sendRejected(tt.err); got != tt.rejected {
+			t.Errorf("%s: sendRejected = %v, want %v", tt.name, got, tt.rejected)
+		}
+	}
+}
+
+func TestNeverSent(t *testing.T) {
+	rejected := rpcError{-32000, "insufficient funds for gas * price + value"}
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")}
+
+	tests := []struct {
+		name   string
+		status string
+		err    error
+		want   bool
+	}{
+		{"wrong password before signing", TxStatusCreated, errInvalidWalletPassword, true},
+		{"gas estimate failed before signing", TxStatusApproved, rejected, true},
+		{"node rejected the send", TxStatusSigned, rejected, true},
+		{"send timed out", TxStatusSigned, timeout, false},
+		{"send cancelled", TxStatusSigned, context.Canceled, false},
+	}
+	for _, tt := range tests {
+		got := neverSent(db.Transaction{Status: tt.status}, tt.err)
+		if got != tt.want {
+			t.Errorf("%s: neverSent = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseNonce(t *testing.T) {
+	var released []any
+	fake := newFakeDB()
+	fake.on("ReleaseNonce", func(args []any) ([]any, error) {
+		released = args
+		return nil, nil
+	})
+	server := &Server{q: db.New(fake)}
+
+	server.releaseNonce(context.Background(), 11155111, "0xabc", 7)
+	if len(released) != 3 || released[0] != int32(11155111) || released[1] != "0xabc" || released[2] != int64(7) {
+		t.Errorf("ReleaseNonce args = %v, want [11155111 0xabc 7]", released)
+	}
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Spending policy kinds, stored in spending_policies.kind. Amount-based kinds
+// apply to transfers of one asset: the chain's native coin, or the token at
+// token_address. Amounts are in the asset's base units.
+const (
+	// PolicyKindMaxAmount caps the value of a single transaction.
+	PolicyKindMaxAmount = "max_amount"
+	// PolicyKindRollingLimit caps the total sent over the last
+	// window_seconds.
+	PolicyKindRollingLimit = "rolling_limit"
+	// PolicyKindDailyLimit caps the total sent since midnight UTC.
+	PolicyKindDailyLimit = "daily_limit"
+	// PolicyKindAllowlist only permits sending to the listed addresses.
+	PolicyKindAllowlist = "allowlist"
+	// PolicyKindDenylist forbids sending to the listed addresses.
+	PolicyKindDenylist = "denylist"
+	// PolicyKindTimeWindow only permits sending between start_minute and
+	// end_minute of the UTC day. The window wraps midnight when start_minute
+	// is after end_minute.
+	PolicyKindTimeWindow = "time_window"
+)
+
+const minutesPerDay = 24 * 60
+
+// spendingLockNamespace is the first key of the advisory lock that
+// serializes a user's sends; the second is the user ID, wrapped into int4
+// range, which at worst makes two users share a lock. Other advisory locks
+// must use a different namespace.
+const spendingLockNamespace = 1
+
+// policyDeletionDelay is how long a deleted policy stays in force, so an
+// owner whose session was taken over has time to cancel the deletion.
+const policyDeletionDelay = 24 * time.Hour
+
+// policyPruneInterval is how often policies past their deletion time are
+// removed.
+const policyPruneInterval = 10 * time.Minute
+
+// PolicyViolation is returned when a spending policy rejects a transaction.
+type PolicyViolation struct {
+	PolicyID int64
+	Kind     string
+	Reason   string
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("rejected by spending policy %d (%s): %s", v.PolicyID, v.Kind, v.Reason)
+}
+
+type CreatePolicyRequest struct {
+	AccountID     *int64   `json:"account_id,omitempty"`
+	ChainID       *int32   `json:"chain_id,omitempty"`
+	Kind          string   `json:"kind"`
+	TokenAddress  string   `json:"token_address,omitempty"`
+	MaxAmount     *big.Int `json:"max_amount,omitempty"`
+	WindowSeconds int32    `json:"window_seconds,omitempty"`
+	Addresses     []string `json:"addresses,omitempty"`
+	StartMinute   *int32   `json:"start_minute,omitempty"`
+	EndMinute     *int32   `json:"end_minute,omitempty"`
+}
+
+type PolicyRequest struct {
+	PolicyID int64 `json:"policy_id"`
+}
+
+type DeletePolicyRequest struct {
+	PolicyID int64  `json:"policy_id"`
+	Password string `json:"password"`
+}
+
+type PolicyResponse struct {
+	ID            int64     `json:"id"`
+	AccountID     *int64    `json:"account_id,omitempty"`
+	ChainID       *int32    `json:"chain_id,omitempty"`
+	Kind          string    `json:"kind"`
+	TokenAddress  string    `json:"token_address,omitempty"`
+	MaxAmount     string    `json:"max_amount,omitempty"`
+	WindowSeconds *int32    `json:"window_seconds,omitempty"`
+	Addresses     []string  `json:"addresses,omitempty"`
+	StartMinute   *int32    `json:"start_minute,omitempty"`
+	EndMinute     *int32    `json:"end_minute,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	// DeleteAfter is when a pending deletion takes effect.
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+}
+
+type ListPoliciesResponse struct {
+	Policies []PolicyResponse `json:"policies"`
+}
+
+func newPolicyResponse(p db.SpendingPolicy) PolicyResponse {
+	response := PolicyResponse{
+		ID:           p.ID,
+		Kind:         p.Kind,
+		TokenAddress: p.TokenAddress.String,
+		Addresses:    p.Addresses,
+		CreatedAt:    p.CreatedAt.Time,
+	}
+	if p.AccountID.Valid {
+		response.AccountID = &p.AccountID.Int64
+	}
+	if p.ChainID.Valid {
+		response.ChainID = &p.ChainID.Int32
+	}
+	if p.MaxAmount.Valid {
+		response.MaxAmount = store.NumericToBig(p.MaxAmount).String()
+	}
+	if p.WindowSeconds.Valid {
+		response.WindowSeconds = &p.WindowSeconds.Int32
+	}
+	if p.StartMinute.Valid {
+		response.StartMinute = &p.StartMinute.Int32
+	}
+	if p.EndMinute.Valid {
+		response.EndMinute = &p.EndMinute.Int32
+	}
+	if p.DeleteAfter.Valid {
+		response.DeleteAfter = &p.DeleteAfter.Time
+	}
+	return response
+}
+
+func isAmountPolicy(kind string) bool {
+	return kind == PolicyKindMaxAmount || kind == PolicyKindRollingLimit || kind == PolicyKindDailyLimit
+}
+
+// policyParams validates request and turns it into the row to store.
+func policyParams(request *CreatePolicyRequest) (db.CreateSpendingPolicyParams, error) {
+	params := db.CreateSpendingPolicyParams{Kind: request.Kind}
+	if request.AccountID != nil {
+		params.AccountID = pgtype.Int8{Int64: *request.AccountID, Valid: true}
+	}
+	if request.ChainID != nil {
+		params.ChainID = pgtype.Int4{Int32: *request.ChainID, Valid: true}
+	}
+
+	switch request.Kind {
+	case PolicyKindMaxAmount, PolicyKindRollingLimit, PolicyKindDailyLimit:
+		if !params.ChainID.Valid {
+			return params, errors.New("chain_id or account_id is required for amount limits")
+		}
+		if request.MaxAmount == nil || request.MaxAmount.Sign() < 0 {
+			return params, errors.New("max_amount must be a non-negative integer")
+		}
+		params.MaxAmount = store.BigToNumeric(request.MaxAmount)
+		if request.TokenAddress != "" {
+			if !common.IsHexAddress(request.TokenAddress) {
+				return params, errors.New("invalid token_address")
+			}
+			params.TokenAddress = pgtype.Text{String: strings.ToLower(request.TokenAddress), Valid: true}
+		}
+		if request.Kind == PolicyKindRollingLimit {
+			if request.WindowSeconds <= 0 {
+				return params, errors.New("window_seconds must be positive")
+			}
+			params.WindowSeconds = pgtype.Int4{Int32: request.WindowSeconds, Valid: true}
+		}
+
+	case PolicyKindAllowlist, PolicyKindDenylist:
+		if len(request.Addresses) == 0 {
+			return params, errors.New("addresses must not be empty")
+		}
+		for _, address := range request.Addresses {
+			if !common.IsHexAddress(address) {
+				return params, fmt.Errorf("invalid address %q", address)
+			}
+			params.Addresses = append(params.Addresses, strings.ToLower(address))
+		}
+
+	case PolicyKindTimeWindow:
+		if request.StartMinute == nil || request.EndMinute == nil {
+			return params, errors.New("start_minute and end_minute are required")
+		}
+		start, end := *request.StartMinute, *request.EndMinute
+		if start < 0 || start >= minutesPerDay || end < 0 || end >= minutesPerDay || start == end {
+			return params, fmt.Errorf("start_minute and end_minute must be distinct minutes of the day (0-%d)", minutesPerDay-1)
+		}
+		params.StartMinute = pgtype.Int4{Int32: start, Valid: true}
+		params.EndMinute = pgtype.Int4{Int32: end, Valid: true}
+
+	default:
+		return params, fmt.Errorf("unknown policy kind %q", request.Kind)
+	}
+	return params, nil
+}
+
+// CreatePolicy adds a spending policy for the caller. A policy with an
+// account_id applies to that account only; otherwise it applies to all of
+// the caller's accounts, optionally restricted to chain_id.
+func (server *Server) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &CreatePolicyRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	if request.AccountID != nil {
+		account, ok := server.authorizeAccount(w, r, *request.AccountID)
+		if !ok {
+			return
+		}
+		if request.ChainID != nil && *request.ChainID != account.ChainID {
+			respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "chain_id does not match the account")
+			return
+		}
+		request.ChainID = &account.ChainID
+	}
+
+	params, err := policyParams(request)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, err.Error())
+		return
+	}
+	params.UserID = callerID(r.Context())
+
+	policy, err := server.q.CreateSpendingPolicy(r.Context(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusCreated, newPolicyResponse(policy))
+}
+
+func (server *Server) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	policies, err := server.q.ListSpendingPoliciesByUserId(r.Context(), callerID(r.Context()))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListPoliciesResponse{Policies: make([]PolicyResponse, 0, len(policies))}
+	for _, policy := range policies {
+		response.Policies = append(response.Policies, newPolicyResponse(policy))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// ownedPolicy loads policyID and checks that it belongs to the caller. On
+// failure it writes the error response and returns false.
+func (server *Server) ownedPolicy(w http.ResponseWriter, r *http.Request, policyID int64) (db.SpendingPolicy, bool) {
+	policy, err := server.q.GetSpendingPolicyById(r.Context(), policyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codePolicyNotFound, "Policy not found")
+			return policy, false
+		}
+		writeError(w, err)
+		return policy, false
+	}
+	if policy.UserID != callerID(r.Context()) {
+		respond.WriteError(w, http.StatusForbidden, respond.CodeForbidden, "Policy does not belong to caller")
+		return policy, false
+	}
+	return policy, true
+}
+
+// DeletePolicy schedules a policy for deletion. Removing a policy loosens the
+// caller's protection, so it takes the wallet password and only takes effect
+// after policyDeletionDelay; until then the policy is enforced and the
+// deletion can be withdrawn through CancelPolicyDeletion. Deleting a policy
+// that is already scheduled keeps its original time.
+func (server *Server) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &DeletePolicyRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	policy, ok := server.ownedPolicy(w, r, request.PolicyID)
+	if !ok {
+		return
+	}
+
+	err = server.verifyWalletPassword(r.Context(), policy.UserID, request.Password)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	policy, err = server.q.ScheduleSpendingPolicyDeletion(r.Context(), db.ScheduleSpendingPolicyDeletionParams{
+		ID:          policy.ID,
+		DeleteAfter: timestamp(time.Now().UTC().Add(policyDeletionDelay)),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusAccepted, newPolicyResponse(policy))
+}
+
+// CancelPolicyDeletion withdraws a pending deletion, keeping the policy.
+func (server *Server) CancelPolicyDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &PolicyRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	policy, ok := server.ownedPolicy(w, r, request.PolicyID)
+	if !ok {
+		return
+	}
+
+	policy, err = server.q.CancelSpendingPolicyDeletion(r.Context(), policy.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newPolicyResponse(policy))
+}
+
+// PrunePolicies periodically removes policies whose deletion took effect.
+// They already stopped applying at delete_after; this only drops the rows.
+func (server *Server) PrunePolicies() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ticker := time.NewTicker(policyPruneInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := server.q.DeleteDueSpendingPolicies(context.Background(), timestamp(time.Now().UTC()))
+		if err != nil {
+			logger.Error("Failed to prune deleted spending policies",
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// checkSpendingPolicies evaluates every policy covering the transaction about
+// to be created from params and returns a *PolicyViolation for the first one
+// it breaks. Callers must hold LockUserSpending for userID so that limits
+// account for concurrent sends.
+func checkSpendingPolicies(ctx context.Context, q *db.Queries, userID int64, params db.CreateTransactionParams, now time.Time) error {
+	policies, err := q.ListSpendingPoliciesForAccount(ctx, db.ListSpendingPoliciesForAccountParams{
+		UserID:    userID,
+		AccountID: pgtype.Int8{Int64: params.AccountID, Valid: true},
+		ChainID:   pgtype.Int4{Int32: params.ChainID, Valid: true},
+		Now:       timestamp(now),
+	})
+	if err != nil {
+		return err
+	}
+
+	amount := store.NumericToBig(params.Value)
+	to := strings.ToLower(params.ToAddress)
+	token := pgtype.Text{String: strings.ToLower(params.TokenAddress.String), Valid: params.TokenAddress.Valid}
+
+	for _, policy := range policies {
+		if isAmountPolicy(policy.Kind) && policy.TokenAddress != token {
+			continue
+		}
+
+		reason, err := evaluatePolicy(ctx, q, policy, userID, params.ChainID, token, to, amount, now)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return &PolicyViolation{PolicyID: policy.ID, Kind: policy.Kind, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// evaluatePolicy returns why policy rejects sending amount to to, or "" if it
+// allows it.
+func evaluatePolicy(ctx context.Context, q *db.Queries, policy db.SpendingPolicy, userID int64, chainID int32, token pgtype.Text, to string, amount *big.Int, now time.Time) (string, error) {
+	switch policy.Kind {
+	case PolicyKindMaxAmount:
+		limit := store.NumericToBig(policy.MaxAmount)
+		if amount.Cmp(limit) > 0 {
+			return fmt.Sprintf("amount %s exceeds the per-transaction cap of %s", amount, limit), nil
+		}
+
+	case PolicyKindRollingLimit, PolicyKindDailyLimit:
+		since, period := spendingWindow(policy, now)
+		sent, err := q.SumOutgoingValue(ctx, db.SumOutgoingValueParams{
+			UserID:       userID,
+			AccountID:    policy.AccountID,
+			ChainID:      chainID,
+			TokenAddress: token,
+			Since:        timestamp(since),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		limit := store.NumericToBig(policy.MaxAmount)
+		total := new(big.Int).Add(store.NumericToBig(sent), amount)
+		if total.Cmp(limit) > 0 {
+			return fmt.Sprintf("sending %s would bring the total sent %s to %s, above the limit of %s", amount, period, total, limit), nil
+		}
+
+	case PolicyKindAllowlist:
+		if !containsAddress(policy.Addresses, to) {
+			return fmt.Sprintf("destination %s is not on the allowlist", to), nil
+		}
+
+	case PolicyKindDenylist:
+		if containsAddress(policy.Addresses, to) {
+			return fmt.Sprintf("destination %s is on the denylist", to), nil
+		}
+
+	case PolicyKindTimeWindow:
+		start, end := policy.StartMinute.Int32, policy.EndMinute.Int32
+		minute := int32(now.Hour()*60 + now.Minute())
+		inside := start <= minute && minute < end
+		if start > end {
+			inside = minute >= start || minute < end
+		}
+		if !inside {
+			return fmt.Sprintf("sending is only allowed between %s and %s UTC", clockTime(start), clockTime(end)), nil
+		}
+	}
+	return "", nil
+}
+
+// spendingWindow returns when the window of a rolling or daily limit
+// evaluated at now starts, and how to describe it in a rejection.
+func spendingWindow(policy db.SpendingPolicy, now time.Time) (time.Time, string) {
+	if policy.Kind == PolicyKindRollingLimit {
+		window := time.Duration(policy.WindowSeconds.Int32) * time.Second
+		return now.Add(-window), "in the last " + window.String()
+	}
+	return now.UTC().Truncate(24 * time.Hour), "today (UTC)"
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, candidate := range addresses {
+		if candidate == address {
+			return true
+		}
+	}
+	return false
+}
+
+func clockTime(minute int32) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSpendingWindow(t *testing.T) {
+	now := time.Date(2024, 6, 12, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		policy db.SpendingPolicy
+		since  time.Time
+		period string
+	}{
+		{
+			"rolling hour",
+			db.SpendingPolicy{Kind: PolicyKindRollingLimit, WindowSeconds: pgtype.Int4{Int32: 3600, Valid: true}},
+			now.Add(-time.Hour),
+			"in the last 1h0m0s",
+		},
+		{
+			"rolling week",
+			db.SpendingPolicy{Kind: PolicyKindRollingLimit, WindowSeconds: pgtype.Int4{Int32: 7 * 86400, Valid: true}},
+			now.Add(-7 * 24 * time.Hour),
+			"in the last 168h0m0s",
+		},
+		{
+			"daily",
+			db.SpendingPolicy{Kind: PolicyKindDailyLimit},
+			time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC),
+			"today (UTC)",
+		},
+	}
+	for _, tt := range tests {
+		since, period := spendingWindow(tt.policy, now)
+		if !since.Equal(tt.since) || period != tt.period {
+			t.Errorf("%s: window = %v %q, want %v %q", tt.name, since, period, tt.since, tt.period)
+		}
+	}
+
+	// The day starts at midnight UTC whatever zone now is given in.
+	local := now.In(time.FixedZone("UTC+9", 9*3600))
+	since, _ := spendingWindow(db.SpendingPolicy{Kind: PolicyKindDailyLimit}, local)
+	if !since.Equal(time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily window from UTC+9 starts at %v, want midnight UTC", since)
+	}
+}
+
+func TestEvaluateSpendingLimit(t *testing.T) {
+	now := time.Date(2024, 6, 12, 15, 4, 5, 0, time.UTC)
+	rolling := db.SpendingPolicy{
+		ID:            1,
+		Kind:          PolicyKindRollingLimit,
+		MaxAmount:     store.BigToNumeric(big.NewInt(1000)),
+		WindowSeconds: pgtype.Int4{Int32: 3600, Valid: true},
+	}
+	daily := db.SpendingPolicy{
+		ID:        2,
+		Kind:      PolicyKindDailyLimit,
+		MaxAmount: store.BigToNumeric(big.NewInt(1000)),
+	}
+
+	tests := []struct {
+		name     string
+		policy   db.SpendingPolicy
+		sent     int64
+		amount   int64
+		since    time.Time
+		rejected bool
+	}{
+		{"rolling, nothing sent", rolling, 0, 1000, now.Add(-time.Hour), false},
+		{"rolling, up to the limit", rolling, 400, 600, now.Add(-time.Hour), false},
+		{"rolling, over the limit", rolling, 400, 601, now.Add(-time.Hour), true},
+		{"rolling, single send over the limit", rolling, 0, 1001, now.Add(-time.Hour), true},
+		{"daily, up to the limit", daily, 999, 1, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), false},
+		{"daily, over the limit", daily, 1000, 1, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		var since time.Time
+		fake := newFakeDB()
+		fake.on("SumOutgoingValue", func(args []any) ([]any, error) {
+			since = args[4].(pgtype.Timestamp).Time
+			return []any{store.BigToNumeric(big.NewInt(tt.sent))}, nil
+		})
+
+		reason, err := evaluatePolicy(context.Background(), db.New(fake), tt.policy, 7, 1, pgtype.Text{}, "0x1", big.NewInt(tt.amount), now)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if (reason != "") != tt.rejected {
+			t.Errorf("%s: reason = %q, want rejected %v", tt.name, reason, tt.rejected)
+		}
+		if !since.Equal(tt.since) {
+			t.Errorf("%s: summed sends since %v, want %v", tt.name, since, tt.since)
+		}
+	}
+}
+
+// policyStore serves the policy queries of DeletePolicy for policy, owned by
+// a user whose wallet password is password, and counts scheduled deletions.
+func policyStore(t *testing.T, fake *fakeDB, policy *db.SpendingPolicy, password string) *int {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduled := 0
+	fake.on("GetSpendingPolicyById", func(args []any) ([]any, error) {
+		return columns(*policy), nil
+	})
+	fake.on("GetUserById", func(args []any) ([]any, error) {
+		return columns(db.User{ID: args[0].(int64), WalletHashPassword: string(hash)}), nil
+	})
+	fake.on("ScheduleSpendingPolicyDeletion", func(args []any) ([]any, error) {
+		scheduled++
+		if !policy.DeleteAfter.Valid {
+			policy.DeleteAfter = args[0].(pgtype.Timestamp)
+		}
+		return columns(*policy), nil
+	})
+	return &scheduled
+}
+
+func TestDeletePolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		caller    int64
+		password  string
+		status    int
+		code      string
+		scheduled int
+	}{
+		{"owner with wallet password", 7, "wallet-password", http.StatusAccepted, "", 1},
+		{"owner with wrong password", 7, "guess", http.StatusUnauthorized, codeInvalidWalletPassword, 0},
+		{"owner without password", 7, "", http.StatusUnauthorized, codeInvalidWalletPassword, 0},
+		{"another user", 8, "wallet-password", http.StatusForbidden, respond.CodeForbidden, 0},
+	}
+	for _, tt := range tests {
+		policy := &db.SpendingPolicy{ID: 3, UserID: 7, Kind: PolicyKindDailyLimit}
+		fake := newFakeDB()
+		scheduled := policyStore(t, fake, policy, "wallet-password")
+		server := &Server{q: db.New(fake)}
+
+		body, _ := json.Marshal(DeletePolicyRequest{PolicyID: policy.ID, Password: tt.password})
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/account/delete_policy", bytes.NewReader(body))
+		request = request.WithContext(context.WithValue(request.Context(), userIDContextKey, tt.caller))
+		recorder := httptest.NewRecorder()
+		before := time.Now().UTC()
+		server.DeletePolicy(recorder, request)
+
+		if recorder.Code != tt.status {
+			t.Fatalf("%s: status = %d, want %d: %s", tt.name, recorder.Code, tt.status, recorder.Body)
+		}
+		if tt.code != "" && errorCode(t, recorder) != tt.code {
+			t.Errorf("%s: code = %q, want %q", tt.name, errorCode(t, recorder), tt.code)
+		}
+		if *scheduled != tt.scheduled {
+			t.Errorf("%s: scheduled %d deletions, want %d", tt.name, *scheduled, tt.scheduled)
+		}
+		if tt.status != http.StatusAccepted {
+			continue
+		}
+
+		var response PolicyResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.DeleteAfter == nil || response.DeleteAfter.Before(before.Add(policyDeletionDelay)) {
+			t.Errorf("%s: delete_after = %v, want at least %v from now", tt.name, response.DeleteAfter, policyDeletionDelay)
+		}
+	}
+}
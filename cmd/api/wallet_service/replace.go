@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	errNotReplaceable   = errors.New("only pending broadcast transactions can be replaced")
+	errAlreadyReplacing = errors.New("transaction is already being replaced")
+)
+
+type ReplaceTransactionRequest struct {
+	TransactionID        int64    `json:"transaction_id"`
+	Password             string   `json:"password"`
+	MaxFeePerGas         *big.Int `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas *big.Int `json:"max_priority_fee_per_gas,omitempty"`
+}
+
+// SpeedUpTransaction resends a pending transaction with the same nonce and a
+// higher fee.
+func (server *Server) SpeedUpTransaction(w http.ResponseWriter, r *http.Request) {
+	server.replaceTransaction(w, r, false)
+}
+
+// CancelTransaction replaces a pending transaction with a 0-value transfer to
+// the sender itself, so the original can no longer be mined.
+func (server *Server) CancelTransaction(w http.ResponseWriter, r *http.Request) {
+	server.replaceTransaction(w, r, true)
+}
+
+func (server *Server) replaceTransaction(w http.ResponseWriter, r *http.Request, cancel bool) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &ReplaceTransactionRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	original, err := server.q.GetTransactionById(r.Context(), request.TransactionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeTransactionNotFound, "Transaction not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeAccount(w, r, original.AccountID); !ok {
+		return
+	}
+
+	chainID := strconv.Itoa(int(original.ChainID))
+	client, err := server.clients.Client(chainID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var record db.Transaction
+	err = server.store.ExecTx(r.Context(), func(q *db.Queries) error {
+		// Lock the original so concurrent requests cannot both see no live
+		// replacement and each create one.
+		original, err = q.LockTransaction(r.Context(), original.ID)
+		if err != nil {
+			return err
+		}
+		if original.Status != TxStatusBroadcast {
+			return errNotReplaceable
+		}
+
+		live, err := q.GetLiveReplacement(r.Context(), pgtype.Int8{Int64: original.ID, Valid: true})
+		if err == nil {
+			return fmt.Errorf("%w by transaction %d", errAlreadyReplacing, live.ID)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		params := db.CreateReplacementTransactionParams{
+			AccountID:    original.AccountID,
+			ChainID:      original.ChainID,
+			FromAddress:  original.FromAddress,
+			ToAddress:    original.ToAddress,
+			Value:        original.Value,
+			TokenAddress: original.TokenAddress,
+			Nonce:        original.Nonce,
+			ReplacesID:   pgtype.Int8{Int64: original.ID, Valid: true},
+		}
+		if cancel {
+			params.ToAddress = original.FromAddress
+			params.Value = store.BigToNumeric(new(big.Int))
+			params.TokenAddress = pgtype.Text{}
+		}
+
+		record, err = q.CreateReplacementTransaction(r.Context(), params)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	feeOpts := FeeOptions{
+		MaxFeePerGas:         request.MaxFeePerGas,
+		MaxPriorityFeePerGas: request.MaxPriorityFeePerGas,
+	}
+	record, err = server.makeTransaction(r.Context(), record, request.Password, feeOpts, client)
+	if err != nil {
+		if neverSent(record, err) {
+			server.failTransaction(r.Context(), record, err)
+		}
+		writeError(w, err)
+		return
+	}
+
+	response := &CreateTransactionResponse{
+		Messsage:        "Replacement transaction created!",
+		TransactionID:   record.ID,
+		TransactionHash: record.Hash.String,
+		ToAddress:       record.ToAddress,
+		Status:          record.Status,
+	}
+
+	respond.JSON(w, http.StatusCreated, *response)
+}
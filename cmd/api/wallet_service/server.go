@@ -9,31 +9,28 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/Dev317/golang_wallet/chain"
 	cf "github.com/Dev317/golang_wallet/config/wallet"
 	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
-	"github.com/jackc/pgx/v5/pgxpool"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/queue"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
 )
 
+// outboxProducer tags this service's outbox rows; the scanner relays its own
+// from the same table.
+const outboxProducer = "wallet_service"
+
 type Server struct {
-	config    cf.Config
-	ethConfig cf.EthereumConfig
+	config      cf.Config
+	ethConfig   cf.EthereumConfig
 	queueConfig cf.QueueConifg
-	q         *db.Queries
-	s         *http.Server
-	queueConn *amqp.Connection
-}
-
-func makeQuery(config cf.Config) *db.Queries {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	ctx := context.Background()
-	d, err := pgxpool.New(ctx, makeConnString(config))
-	if err != nil {
-		logger.Error("Failed to create connection pool",
-			slog.Any("error", err),
-		)
-	}
-	return db.New(d)
+	store       *store.Store
+	q           *db.Queries
+	s           *http.Server
+	bus         queue.Bus
+	clients     *chain.Registry
 }
 
 func makeHTTPServer(config cf.Config) *http.Server {
@@ -45,38 +42,25 @@ func makeHTTPServer(config cf.Config) *http.Server {
 	}
 }
 
-func makeQueueConnection(queueConfig cf.QueueConifg) (*amqp.Connection, error) {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	conn, err := amqp.Dial(queueConfig.URI)
-	if err != nil {
-		logger.Error("Failed to connect to RabbitMQ",
-			slog.Any("error", err),
-		)
-		return nil, err
-	}
-	return conn, nil
+// makeBus opens the message bus named by the queue URI. For RabbitMQ the
+// connection is kept alive in the background and the queues this service
+// publishes to and consumes from are declared on every reconnect.
+func makeBus(queueConfig cf.QueueConifg) queue.Bus {
+	return queue.Open(queueConfig.URI, event.ScanQueue, event.ResultQueue, event.DepositQueue)
 }
 
 func NewServer(config cf.Config, ethConfig cf.EthereumConfig, queueConfig cf.QueueConifg) *Server {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	q := makeQuery(config)
+	database := store.Open(config.Database())
 	s := makeHTTPServer(config)
 
-	queueConn, err := makeQueueConnection(queueConfig)
-	if err != nil {
-		logger.Error("Failed to connect to RabbitMQ",
-			slog.Any("error", err),
-		)
-		os.Exit(1)
-	}
-	logger.Info("Connected to RabbitMQ")
-
 	server := &Server{
 		config:    config,
 		ethConfig: ethConfig,
-		q:         q,
+		store:     database,
+		q:         database.Queries(),
 		s:         s,
-		queueConn: queueConn,
+		bus:       makeBus(queueConfig),
+		clients:   chain.NewRegistry(ethConfig.ChainURLs(), config.RPCOptions()),
 	}
 
 	mux := http.NewServeMux()
@@ -84,25 +68,55 @@ func NewServer(config cf.Config, ethConfig cf.EthereumConfig, queueConfig cf.Que
 	return server
 }
 
-func makeConnString(config cf.Config) string {
-	return "user=" + config.DBUser + " password=" + config.DBPassword + " dbname=" + config.DBName + " sslmode=" + config.DBSSLMode + " host=" + config.DBHost + " port=" + config.DBPort
-}
-
 func (server *Server) SetupRoutes(mux *http.ServeMux) {
 	user := http.NewServeMux()
 	user.HandleFunc("/create", server.CreateUser)
+	user.HandleFunc("/login", server.Login)
+	user.HandleFunc("/refresh", server.RefreshSession)
+	user.HandleFunc("/logout", server.Logout)
 
 	account := http.NewServeMux()
 	account.HandleFunc("/create", server.CreateAccount)
-	account.HandleFunc("/create_transaction", server.CreateTransaction)
+	account.HandleFunc("/list_accounts", server.ListAccounts)
+	account.HandleFunc("/get_balance", server.GetBalance)
+	account.HandleFunc("/create_transaction", server.idempotent(server.CreateTransaction))
+	account.HandleFunc("/create_token_transaction", server.idempotent(server.CreateTokenTransaction))
+	account.HandleFunc("/speed_up_transaction", server.idempotent(server.SpeedUpTransaction))
+	account.HandleFunc("/cancel_transaction", server.idempotent(server.CancelTransaction))
+	account.HandleFunc("/get_transaction", server.GetTransaction)
+	account.HandleFunc("/list_transactions", server.ListTransactions)
+	account.HandleFunc("/create_policy", server.CreatePolicy)
+	account.HandleFunc("/list_policies", server.ListPolicies)
+	account.HandleFunc("/delete_policy", server.DeletePolicy)
+	account.HandleFunc("/cancel_policy_deletion", server.CancelPolicyDeletion)
+	account.HandleFunc("/create_contact", server.CreateContact)
+	account.HandleFunc("/list_contacts", server.ListContacts)
+	account.HandleFunc("/update_contact", server.UpdateContact)
+	account.HandleFunc("/delete_contact", server.DeleteContact)
+	account.HandleFunc("/create_approval_rule", server.CreateApprovalRule)
+	account.HandleFunc("/list_approval_rules", server.ListApprovalRules)
+	account.HandleFunc("/delete_approval_rule", server.DeleteApprovalRule)
+	account.HandleFunc("/get_approval_rule_deletion", server.GetApprovalRuleDeletion)
+	account.HandleFunc("/approve_approval_rule_deletion", server.ApproveApprovalRuleDeletion)
+	account.HandleFunc("/approve_transaction", server.ApproveTransaction)
+	account.HandleFunc("/reject_transaction", server.RejectTransaction)
+	account.HandleFunc("/execute_transaction", server.idempotent(server.ExecuteTransaction))
+	account.HandleFunc("/get_approval", server.GetApproval)
+	account.HandleFunc("/list_pending_approvals", server.ListPendingApprovals)
+	account.HandleFunc("/create_webhook", server.CreateWebhook)
+	account.HandleFunc("/list_webhooks", server.ListWebhooks)
+	account.HandleFunc("/delete_webhook", server.DeleteWebhook)
+	account.HandleFunc("/list_webhook_deliveries", server.ListWebhookDeliveries)
+	account.HandleFunc("/redeliver_webhook", server.RedeliverWebhook)
+
+	admin := http.NewServeMux()
+	admin.HandleFunc("/delete_approval_rule", server.AdminDeleteApprovalRule)
 
 	mux.Handle("/api/v1/user/", http.StripPrefix("/api/v1/user", user))
-	mux.Handle("/api/v1/account/", http.StripPrefix("/api/v1/account", account))
+	mux.Handle("/api/v1/account/", http.StripPrefix("/api/v1/account", server.authenticate(account)))
+	mux.Handle("/api/v1/admin/", http.StripPrefix("/api/v1/admin", server.requireAdmin(admin)))
 
-	mux.HandleFunc("/api/v1/health-check", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("message: Service is healthy!"))
-	})
+	mux.HandleFunc("/api/v1/health-check", server.HealthCheck)
 
 	server.s.Handler = mux
 }
@@ -113,6 +127,16 @@ func (server *Server) Start() {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	// Sends must not start before nonces agree with the chain again.
+	reconcileCtx, cancelReconcile := context.WithTimeout(context.Background(), 30*time.Second)
+	err := server.reconcileNonces(reconcileCtx)
+	cancelReconcile()
+	if err != nil {
+		logger.Error("Failed to reconcile nonces",
+			slog.Any("error", err),
+		)
+	}
+
 	go func() {
 		if err := server.s.ListenAndServe(); err != nil {
 			logger.Error("Server error",
@@ -122,6 +146,14 @@ func (server *Server) Start() {
 	}()
 	logger.Info("Server started successfully")
 
+	go server.store.RelayOutbox(server.bus, outboxProducer)
+	go server.DeliverWebhooks()
+	go server.ExpireApprovals()
+	go server.PruneIdempotencyKeys()
+	go server.PrunePolicies()
+	go server.Consume(event.ResultQueue, server.handleTransactionResult)
+	go server.Consume(event.DepositQueue, server.handleDeposit)
+
 	<-done
 	logger.Warn("Server stopped!")
 
@@ -132,8 +164,26 @@ func (server *Server) Start() {
 		logger.Error("Failed to shutdown server",
 			slog.Any("error", err),
 		)
-		server.queueConn.Close()
 	}
+	server.bus.Close()
+	server.clients.Close()
 
 	logger.Warn("Server shutdown successfully")
 }
+
+type HealthResponse struct {
+	Message string `json:"message"`
+	Queue   string `json:"queue"`
+}
+
+// HealthCheck reports 503 while the message bus is down, since neither
+// transaction events nor results flow until it is back.
+func (server *Server) HealthCheck(w http.ResponseWriter, r *http.Request) {
+	state := server.bus.State()
+	if state != queue.StateConnected {
+		respond.JSON(w, http.StatusServiceUnavailable, HealthResponse{Message: "Service is degraded!", Queue: state})
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, HealthResponse{Message: "Service is healthy!", Queue: state})
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	cf "github.com/Dev317/golang_wallet/config/wallet"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// erc20ABIJSON covers the parts of the ERC-20 interface the wallet calls.
+const erc20ABIJSON = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]}
+]`
+
+var erc20ABI = mustParseABI(erc20ABIJSON)
+
+var (
+	errUnknownToken  = errors.New("unknown token")
+	errInvalidAmount = errors.New("invalid amount")
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// chainConfig returns the configuration for chainID.
+func (server *Server) chainConfig(chainID string) (cf.ChainItemConfig, bool) {
+	for _, chainItem := range server.ethConfig.ChainItemList {
+		if chainItem.ChainID == chainID {
+			return chainItem, true
+		}
+	}
+	return cf.ChainItemConfig{}, false
+}
+
+// resolveToken looks token up by symbol or contract address in the chain's
+// token registry. Contracts missing from the registry are accepted by address,
+// with their decimals read from the chain.
+func (server *Server) resolveToken(ctx context.Context, client *ethclient.Client, chainID, token string) (cf.TokenConfig, error) {
+	chainItem, _ := server.chainConfig(chainID)
+	for _, registered := range chainItem.Tokens {
+		if strings.EqualFold(registered.Symbol, token) || strings.EqualFold(registered.Address, token) {
+			return registered, nil
+		}
+	}
+
+	if !common.IsHexAddress(token) {
+		return cf.TokenConfig{}, fmt.Errorf("%w: %s", errUnknownToken, token)
+	}
+
+	decimals, err := tokenDecimals(ctx, client, common.HexToAddress(token))
+	if err != nil {
+		return cf.TokenConfig{}, err
+	}
+	return cf.TokenConfig{Address: common.HexToAddress(token).Hex(), Decimals: decimals}, nil
+}
+
+func tokenDecimals(ctx context.Context, client *ethclient.Client, token common.Address) (uint8, error) {
+	data, err := erc20ABI.Pack("decimals")
+	if err != nil {
+		return 0, err
+	}
+	output, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return 0, err
+	}
+	values, err := erc20ABI.Unpack("decimals", output)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s does not look like an ERC-20 contract", errUnknownToken, token.Hex())
+	}
+	return values[0].(uint8), nil
+}
+
+// parseTokenAmount converts a human-readable decimal amount such as "1.5" into
+// base units for a token with the given number of decimals.
+func parseTokenAmount(amount string, decimals uint8) (*big.Int, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(amount), ".")
+	if whole == "" && frac == "" {
+		return nil, errInvalidAmount
+	}
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("%w: more than %d decimal places", errInvalidAmount, decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("%w: %q", errInvalidAmount, amount)
+		}
+	}
+
+	value, _ := new(big.Int).SetString(digits, 10)
+	if value.Sign() == 0 {
+		return nil, fmt.Errorf("%w: must be positive", errInvalidAmount)
+	}
+	return value, nil
+}
+
+// transferCall returns the destination, value and calldata of the on-chain
+// call that sends value to to: a plain value transfer when token is empty,
+// otherwise an ERC-20 transfer sent to the token contract.
+func transferCall(to common.Address, value *big.Int, token string) (common.Address, *big.Int, []byte, error) {
+	if token == "" {
+		return to, value, nil, nil
+	}
+	data, err := erc20ABI.Pack("transfer", to, value)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return common.HexToAddress(token), new(big.Int), data, nil
+}
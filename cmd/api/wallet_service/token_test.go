@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTokenAmount(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+		want     string
+	}{
+		{"1", 18, "1000000000000000000"},
+		{"1.5", 18, "1500000000000000000"},
+		{"0.000001", 6, "1"},
+		{".5", 6, "500000"},
+		{"2.", 6, "2000000"},
+		{" 3 ", 0, "3"},
+		{"0012.340", 3, "12340"},
+		{"115792089237316195423570985008687907853269984665640564039457.584007913129639935", 18, "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+	}
+	for _, tt := range tests {
+		got, err := parseTokenAmount(tt.amount, tt.decimals)
+		if err != nil {
+			t.Errorf("parseTokenAmount(%q, %d): %v", tt.amount, tt.decimals, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("parseTokenAmount(%q, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseTokenAmountRejects(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals uint8
+	}{
+		{"", 18},
+		{".", 18},
+		{"0", 18},
+		{"0.000", 18},
+		{"1.0000001", 6},
+		{"1.5", 0},
+		{"-1", 18},
+		{"+1", 18},
+		{"1e18", 18},
+		{"1,5", 18},
+		{"1.2.3", 18},
+		{"0x10", 18},
+	}
+	for _, tt := range tests {
+		_, err := parseTokenAmount(tt.amount, tt.decimals)
+		if !errors.Is(err, errInvalidAmount) {
+			t.Errorf("parseTokenAmount(%q, %d) error = %v, want errInvalidAmount", tt.amount, tt.decimals, err)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Transaction lifecycle states, stored in transactions.status.
+const (
+	// Transactions that need approval wait in pending_approval until enough
+	// approvers agree (approved) or too many refuse (rejected). Either kind
+	// of request expires after its TTL.
+	TxStatusPendingApproval = "pending_approval"
+	TxStatusApproved        = "approved"
+	TxStatusRejected        = "rejected"
+	TxStatusExpired         = "expired"
+	TxStatusCreated         = "created"
+	TxStatusSigned          = "signed"
+	TxStatusBroadcast       = "broadcast"
+	TxStatusMined           = "mined"
+	TxStatusConfirmed       = "confirmed"
+	TxStatusFailed          = "failed"
+	TxStatusDropped         = "dropped"
+	TxStatusReplaced        = "replaced"
+)
+
+// txTransitions lists the states each status may move to.
+var txTransitions = map[string][]string{
+	TxStatusCreated:         {TxStatusPendingApproval, TxStatusSigned, TxStatusFailed},
+	TxStatusPendingApproval: {TxStatusApproved, TxStatusRejected, TxStatusExpired},
+	TxStatusApproved:        {TxStatusSigned, TxStatusFailed, TxStatusExpired},
+	TxStatusSigned:          {TxStatusBroadcast, TxStatusFailed},
+	TxStatusBroadcast:       {TxStatusMined, TxStatusFailed, TxStatusDropped, TxStatusReplaced},
+	TxStatusMined:           {TxStatusConfirmed, TxStatusFailed, TxStatusBroadcast},
+	// A reorg deeper than the confirmation depth sends a transaction back to
+	// the mempool.
+	TxStatusConfirmed: {TxStatusBroadcast},
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+var (
+	errInvalidTransition = errors.New("invalid transaction status transition")
+	errInvalidPagination = errors.New("invalid pagination")
+)
+
+type TransactionResponse struct {
+	ID                   int64     `json:"id"`
+	AccountID            int64     `json:"account_id"`
+	ChainID              int32     `json:"chain_id"`
+	FromAddress          string    `json:"from_address"`
+	ToAddress            string    `json:"to_address"`
+	Value                string    `json:"value"`
+	TokenAddress         string    `json:"token_address,omitempty"`
+	Nonce                *int64    `json:"nonce,omitempty"`
+	GasLimit             *int64    `json:"gas_limit,omitempty"`
+	TxType               int32     `json:"tx_type"`
+	GasPrice             string    `json:"gas_price,omitempty"`
+	MaxFeePerGas         string    `json:"max_fee_per_gas,omitempty"`
+	MaxPriorityFeePerGas string    `json:"max_priority_fee_per_gas,omitempty"`
+	Hash                 string    `json:"hash,omitempty"`
+	BlockNumber          *int64    `json:"block_number,omitempty"`
+	BlockHash            string    `json:"block_hash,omitempty"`
+	ReplacesID           *int64    `json:"replaces_id,omitempty"`
+	Status               string    `json:"status"`
+	Error                string    `json:"error,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type ListTransactionsResponse struct {
+	Transactions []TransactionResponse `json:"transactions"`
+	Limit        int32                 `json:"limit"`
+	Offset       int32                 `json:"offset"`
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range txTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionTransaction moves tx to the given status. The update is guarded on
+// the current status so concurrent writers cannot skip a state.
+func transitionTransaction(ctx context.Context, q *db.Queries, tx db.Transaction, to string, cause error) (db.Transaction, error) {
+	if !canTransition(tx.Status, to) {
+		return tx, fmt.Errorf("%w: %s -> %s", errInvalidTransition, tx.Status, to)
+	}
+
+	var errText pgtype.Text
+	if cause != nil {
+		logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		logger.Info("Transaction ended with an error",
+			slog.Int64("transaction_id", tx.ID),
+			slog.String("status", to),
+			slog.Any("error", cause),
+		)
+		errText = pgtype.Text{String: transactionError(cause), Valid: true}
+	}
+
+	return q.UpdateTransactionStatus(ctx, db.UpdateTransactionStatusParams{
+		ToStatus:   to,
+		Error:      errText,
+		ID:         tx.ID,
+		FromStatus: tx.Status,
+	})
+}
+
+// transactionErrors maps the causes a transaction can end with to the fixed
+// message recorded for it.
+var transactionErrors = []struct {
+	err     error
+	message string
+}{
+	{errTxReverted, "Transaction reverted on-chain"},
+	{errTxDropped, "Transaction dropped from the network"},
+	{errTxReplaced, "Transaction replaced by another with the same nonce"},
+	{errRejectedByVote, "Rejected by approvers"},
+}
+
+// transactionError returns the message stored in transactions.error, and
+// shown to the owner, for a transaction that ended because of cause. Causes
+// carry node and internal detail, so only a fixed message per kind of
+// failure is kept.
+func transactionError(cause error) string {
+	for _, known := range transactionErrors {
+		if errors.Is(cause, known.err) {
+			return known.message
+		}
+	}
+	for _, known := range knownErrors {
+		if errors.Is(cause, known.err) {
+			return known.message
+		}
+	}
+	if message, ok := respond.ChainErrorMessage(cause); ok {
+		return message
+	}
+	return "Transaction could not be sent"
+}
+
+// neverSent reports whether the makeTransaction error err leaves tx
+// definitely off the network, so it may be marked failed. Once tx is signed
+// only a rejection by the node is definite; after any other error it may
+// have propagated, so it stays signed until the scanner finds its hash mined
+// or dropped.
+func neverSent(tx db.Transaction, err error) bool {
+	return tx.Status != TxStatusSigned || sendRejected(err)
+}
+
+// failTransaction records cause against tx and logs if the status could not be
+// persisted; the original cause is what the caller should surface.
+func (server *Server) failTransaction(ctx context.Context, tx db.Transaction, cause error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	_, err := transitionTransaction(ctx, server.q, tx, TxStatusFailed, cause)
+	if err != nil {
+		logger.Error("Failed to mark transaction as failed",
+			slog.Int64("transaction_id", tx.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func newTransactionResponse(tx db.Transaction) TransactionResponse {
+	response := TransactionResponse{
+		ID:                   tx.ID,
+		AccountID:            tx.AccountID,
+		ChainID:              tx.ChainID,
+		FromAddress:          tx.FromAddress,
+		ToAddress:            tx.ToAddress,
+		Value:                store.NumericString(tx.Value),
+		TokenAddress:         tx.TokenAddress.String,
+		TxType:               tx.TxType,
+		GasPrice:             store.NumericString(tx.GasPrice),
+		MaxFeePerGas:         store.NumericString(tx.MaxFeePerGas),
+		MaxPriorityFeePerGas: store.NumericString(tx.MaxPriorityFeePerGas),
+		Hash:                 tx.Hash.String,
+		BlockHash:            tx.BlockHash.String,
+		Status:               tx.Status,
+		Error:                tx.Error.String,
+		CreatedAt:            tx.CreatedAt.Time,
+		UpdatedAt:            tx.UpdatedAt.Time,
+	}
+	if tx.Nonce.Valid {
+		response.Nonce = &tx.Nonce.Int64
+	}
+	if tx.GasLimit.Valid {
+		response.GasLimit = &tx.GasLimit.Int64
+	}
+	if tx.BlockNumber.Valid {
+		response.BlockNumber = &tx.BlockNumber.Int64
+	}
+	if tx.ReplacesID.Valid {
+		response.ReplacesID = &tx.ReplacesID.Int64
+	}
+	return response
+}
+
+// authorizeAccount loads the account and checks that it belongs to the caller.
+// It writes the error response itself and returns false on failure.
+func (server *Server) authorizeAccount(w http.ResponseWriter, r *http.Request, accountID int64) (db.Account, bool) {
+	account, err := server.q.GetAccountById(r.Context(), accountID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeAccountNotFound, "Account not found")
+			return account, false
+		}
+		writeError(w, err)
+		return account, false
+	}
+
+	if account.UserID != callerID(r.Context()) {
+		respond.WriteError(w, http.StatusForbidden, respond.CodeForbidden, "Account does not belong to caller")
+		return account, false
+	}
+	return account, true
+}
+
+// GetTransaction looks a transaction up by ?id= or ?hash=.
+func (server *Server) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	var tx db.Transaction
+	var err error
+	query := r.URL.Query()
+	switch {
+	case query.Get("id") != "":
+		id, parseErr := strconv.ParseInt(query.Get("id"), 10, 64)
+		if parseErr != nil {
+			respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid id")
+			return
+		}
+		tx, err = server.q.GetTransactionById(r.Context(), id)
+	case query.Get("hash") != "":
+		tx, err = server.q.GetTransactionByHash(r.Context(), pgtype.Text{String: query.Get("hash"), Valid: true})
+	default:
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Either id or hash is required")
+		return
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeTransactionNotFound, "Transaction not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeAccount(w, r, tx.AccountID); !ok {
+		return
+	}
+
+	respond.JSON(w, http.StatusOK, newTransactionResponse(tx))
+}
+
+// ListTransactions pages through an account's transactions, newest first.
+func (server *Server) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	query := r.URL.Query()
+	accountID, err := strconv.ParseInt(query.Get("account_id"), 10, 64)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid account_id")
+		return
+	}
+
+	limit, offset, err := parsePagination(query.Get("limit"), query.Get("offset"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeAccount(w, r, accountID); !ok {
+		return
+	}
+
+	txs, err := server.q.ListTransactionsByAccountId(r.Context(), db.ListTransactionsByAccountIdParams{
+		AccountID: accountID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListTransactionsResponse{
+		Transactions: make([]TransactionResponse, 0, len(txs)),
+		Limit:        limit,
+		Offset:       offset,
+	}
+	for _, tx := range txs {
+		response.Transactions = append(response.Transactions, newTransactionResponse(tx))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// parsePagination reads the limit and offset query parameters, capping limit
+// at maxPageLimit.
+func parsePagination(rawLimit, rawOffset string) (int32, int32, error) {
+	limit := int64(defaultPageLimit)
+	if rawLimit != "" {
+		v, err := strconv.ParseInt(rawLimit, 10, 32)
+		if err != nil || v <= 0 {
+			return 0, 0, fmt.Errorf("%w: limit %q", errInvalidPagination, rawLimit)
+		}
+		limit = min(v, maxPageLimit)
+	}
+
+	var offset int64
+	if rawOffset != "" {
+		v, err := strconv.ParseInt(rawOffset, 10, 32)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("%w: offset %q", errInvalidPagination, rawOffset)
+		}
+		offset = v
+	}
+	return int32(limit), int32(offset), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Dev317/golang_wallet/chain"
+)
+
+func TestTransactionError(t *testing.T) {
+	tests := []struct {
+		name  string
+		cause error
+		want  string
+	}{
+		{"reverted", errTxReverted, "Transaction reverted on-chain"},
+		{"replaced", fmt.Errorf("%w by 0xabc", errTxReplaced), "Transaction replaced by another with the same nonce"},
+		{"rejected by approvers", errRejectedByVote, "Rejected by approvers"},
+		{"known wallet error", fmt.Errorf("decrypt: %w", errInvalidWalletPassword), "Invalid wallet password"},
+		{"node rejection", rpcError{-32000, "insufficient funds for gas * price + value: address 0x1 have 1 want 2"}, "Insufficient funds for value and gas"},
+		{"unavailable chain", fmt.Errorf("%w: 11155111", chain.ErrNoEndpoints), "Chain is temporarily unavailable"},
+		{"anything else", errors.New("dial tcp 10.0.0.5:8545: connection refused"), "Transaction could not be sent"},
+	}
+	for _, tt := range tests {
+		got := transactionError(tt.cause)
+		if got != tt.want {
+			t.Errorf("%s: transactionError = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		limit, offset string
+		wantLimit     int32
+		wantOffset    int32
+		invalid       bool
+	}{
+		{"", "", defaultPageLimit, 0, false},
+		{"5", "10", 5, 10, false},
+		{"100000", "", maxPageLimit, 0, false},
+		{"0", "", 0, 0, true},
+		{"-1", "", 0, 0, true},
+		{"ten", "", 0, 0, true},
+		{"", "-1", 0, 0, true},
+		{"", "99999999999", 0, 0, true},
+	}
+	for _, tt := range tests {
+		limit, offset, err := parsePagination(tt.limit, tt.offset)
+		if tt.invalid {
+			if !errors.Is(err, errInvalidPagination) {
+				t.Errorf("parsePagination(%q, %q): error = %v, want errInvalidPagination", tt.limit, tt.offset, err)
+			}
+			continue
+		}
+		if err != nil || limit != tt.wantLimit || offset != tt.wantOffset {
+			t.Errorf("parsePagination(%q, %q) = %d, %d, %v, want %d, %d", tt.limit, tt.offset, limit, offset, err, tt.wantLimit, tt.wantOffset)
+		}
+	}
+}
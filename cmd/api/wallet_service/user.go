@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/respond"
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
 )
@@ -13,7 +14,8 @@ type CreateUserRequest struct {
 }
 
 type CreateUserResponse struct {
-	Message string `json:"message"`
+	Message  string `json:"message"`
+	Mnemonic string `json:"mnemonic"`
 }
 
 func hashPassword(rawPassword string) (string, error) {
@@ -23,36 +25,48 @@ func hashPassword(rawPassword string) (string, error) {
 
 func (server *Server) CreateUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed!", http.StatusMethodNotAllowed)
+		respond.MethodNotAllowed(w)
 		return
 	}
 
 	newUser := &CreateUserRequest{}
 	err := json.NewDecoder(r.Body).Decode(newUser)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		respond.InvalidBody(w, err)
 		return
 	}
 
 	hashPass, err := hashPassword(newUser.Password)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
-	_, err = server.q.CreateUser(r.Context(), db.CreateUserParams{
-		Email:              newUser.Email,
-		WalletHashPassword: hashPass,
+	var mnemonic string
+	err = server.store.ExecTx(r.Context(), func(q *db.Queries) error {
+		user, err := q.CreateUser(r.Context(), db.CreateUserParams{
+			Email:              newUser.Email,
+			WalletHashPassword: hashPass,
+		})
+		if err != nil {
+			return err
+		}
+
+		mnemonic, _, err = ensureWalletSeed(r.Context(), q, user.ID, newUser.Password)
+		return err
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if respond.IsUniqueViolation(err) {
+			respond.WriteError(w, http.StatusConflict, codeDuplicateEmail, "A user with this email already exists")
+			return
+		}
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
 	response := &CreateUserResponse{
-		Message: "User created successfully!",
+		Message:  "User created successfully!",
+		Mnemonic: mnemonic,
 	}
-	json.NewEncoder(w).Encode(*response)
+	respond.JSON(w, http.StatusCreated, *response)
 }
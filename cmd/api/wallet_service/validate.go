@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Dev317/golang_wallet/respond"
+)
+
+// Units an ether amount can be given in, by their number of decimals.
+var etherUnits = map[string]uint8{
+	"wei":   0,
+	"gwei":  9,
+	"ether": 18,
+}
+
+// ValidationError collects every invalid field of a request, so a client can
+// fix them all at once instead of one round trip per field.
+type ValidationError struct {
+	Fields []respond.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, field.Field+": "+field.Message)
+	}
+	return "invalid request: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, respond.FieldError{Field: field, Message: message})
+}
+
+// orNil returns e, or nil when no field was invalid.
+func (e *ValidationError) orNil() *ValidationError {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// parseEtherAmount converts a decimal amount such as "1.5" in unit (wei, gwei
+// or ether; wei when empty) into wei.
+func parseEtherAmount(amount json.Number, unit string) (*big.Int, error) {
+	if unit == "" {
+		unit = "wei"
+	}
+	decimals, ok := etherUnits[strings.ToLower(unit)]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q, expected wei, gwei or ether", unit)
+	}
+	if amount == "" {
+		return nil, fmt.Errorf("%w: required", errInvalidAmount)
+	}
+	return parseTokenAmount(amount.String(), decimals)
+}
+
+// validateChain checks that chainID is configured.
+func (server *Server) validateChain(e *ValidationError, field, chainID string) {
+	if chainID == "" {
+		e.Add(field, "is required")
+		return
+	}
+	if _, ok := server.chainConfig(chainID); !ok {
+		e.Add(field, fmt.Sprintf("chain %s is not supported", chainID))
+	}
+}
+
+// validateDestination checks that exactly one of toAddress and contactID is
+// given, and that toAddress is a valid checksummed address.
+func validateDestination(e *ValidationError, toAddress string, contactID *int64) {
+	switch {
+	case toAddress != "" && contactID != nil:
+		e.Add("to_address", "give either to_address or contact_id, not both")
+	case toAddress == "" && contactID == nil:
+		e.Add("to_address", "to_address or contact_id is required")
+	case contactID != nil:
+		if *contactID <= 0 {
+			e.Add("contact_id", "must be positive")
+		}
+	default:
+		if _, err := parseAddress(toAddress); err != nil {
+			e.Add("to_address", err.Error())
+		}
+	}
+}
+
+func validateFeeCaps(e *ValidationError, maxFeePerGas, maxPriorityFeePerGas *big.Int) {
+	if maxFeePerGas != nil && maxFeePerGas.Sign() <= 0 {
+		e.Add("max_fee_per_gas", "must be positive")
+	}
+	if maxPriorityFeePerGas != nil && maxPriorityFeePerGas.Sign() < 0 {
+		e.Add("max_priority_fee_per_gas", "must not be negative")
+	}
+}
+
+// validateCreateTransaction checks request and returns the amount in wei.
+func (server *Server) validateCreateTransaction(request *CreateTransactionRequest) (*big.Int, *ValidationError) {
+	e := &ValidationError{}
+	if request.AccountId <= 0 {
+		e.Add("account_id", "is required")
+	}
+	server.validateChain(e, "chain_id", request.ChainId)
+	validateDestination(e, request.ToAddress, request.ContactID)
+	amount, err := parseEtherAmount(request.Amount, request.Unit)
+	if err != nil {
+		e.Add("amount", err.Error())
+	}
+	validateFeeCaps(e, request.MaxFeePerGas, request.MaxPriorityFeePerGas)
+	return amount, e.orNil()
+}
+
+// validateCreateTokenTransaction checks request. The amount can only be
+// converted once the token's decimals are known.
+func (server *Server) validateCreateTokenTransaction(request *CreateTokenTransactionRequest) *ValidationError {
+	e := &ValidationError{}
+	if request.AccountId <= 0 {
+		e.Add("account_id", "is required")
+	}
+	server.validateChain(e, "chain_id", request.ChainId)
+	if request.Token == "" {
+		e.Add("token", "is required")
+	}
+	validateDestination(e, request.ToAddress, request.ContactID)
+	if strings.TrimSpace(request.Amount) == "" {
+		e.Add("amount", "is required")
+	}
+	validateFeeCaps(e, request.MaxFeePerGas, request.MaxPriorityFeePerGas)
+	return e.orNil()
+}
+
+// validateAccountChain checks that the chain a request names is the one its
+// account lives on.
+func validateAccountChain(chainID string, accountChainID int32) *ValidationError {
+	if chainID == fmt.Sprint(accountChainID) {
+		return nil
+	}
+	e := &ValidationError{}
+	e.Add("chain_id", fmt.Sprintf("account is on chain %d", accountChainID))
+	return e
+}
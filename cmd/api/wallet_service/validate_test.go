@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseEtherAmount(t *testing.T) {
+	tests := []struct {
+		amount json.Number
+		unit   string
+		want   string
+	}{
+		{"1", "", "1"},
+		{"1", "wei", "1"},
+		{"1.5", "gwei", "1500000000"},
+		{"0.1", "ether", "100000000000000000"},
+		{"2", "ETHER", "2000000000000000000"},
+	}
+	for _, tt := range tests {
+		got, err := parseEtherAmount(tt.amount, tt.unit)
+		if err != nil {
+			t.Errorf("parseEtherAmount(%q, %q): %v", tt.amount, tt.unit, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("parseEtherAmount(%q, %q) = %s, want %s", tt.amount, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestParseEtherAmountRejects(t *testing.T) {
+	tests := []struct {
+		amount json.Number
+		unit   string
+	}{
+		{"", "ether"},
+		{"1.5", "wei"},
+		{"0.0000000001", "gwei"},
+		{"1", "finney"},
+		{"1e18", "wei"},
+	}
+	for _, tt := range tests {
+		if _, err := parseEtherAmount(tt.amount, tt.unit); err == nil {
+			t.Errorf("parseEtherAmount(%q, %q) succeeded, want an error", tt.amount, tt.unit)
+		}
+	}
+}
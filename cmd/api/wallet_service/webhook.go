@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/respond"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Webhook delivery states, stored in webhook_deliveries.status.
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+const (
+	webhookPollInterval   = time.Second
+	webhookBatchSize      = 20
+	webhookTimeout        = 10 * time.Second
+	webhookMaxAttempts    = 8
+	webhookInitialBackoff = 10 * time.Second
+	webhookMaxBackoff     = time.Hour
+	// webhookLease keeps a claimed delivery from being picked up again while
+	// its attempt is in flight.
+	webhookLease = time.Minute
+)
+
+// Headers sent with every webhook request. The signature is the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the subscription secret.
+const (
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// webhookEventTypes are the events a subscription can receive.
+var webhookEventTypes = []string{
+	event.TypeTransactionMined,
+	event.TypeTransactionConfirmed,
+	event.TypeTransactionReorged,
+	event.TypeTransactionFailed,
+	event.TypeDepositDetected,
+	event.TypeDepositConfirmed,
+	event.TypeDepositReverted,
+}
+
+var errWebhookDestination = errors.New("webhook destination is not a public address")
+
+// webhookClient only connects to public addresses. The check runs on the
+// address actually dialed, after DNS resolution and on every redirect, so a
+// hostname that resolves, or later rebinds, to an internal address is refused
+// as well. Proxies are not used since they would dial on our behalf.
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: webhookTimeout,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				ip, err := netip.ParseAddr(host)
+				if err != nil || !isPublicAddr(ip) {
+					return fmt.Errorf("%w: %s", errWebhookDestination, host)
+				}
+				return nil
+			},
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: webhookTimeout,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not routable on
+// the internet but not covered by netip's IsPrivate either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddr reports whether ip may be the target of a webhook: not
+// loopback, private, link-local (which includes cloud metadata endpoints),
+// unspecified or multicast.
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+type WebhookRequest struct {
+	WebhookID int64 `json:"webhook_id"`
+}
+
+type RedeliverWebhookRequest struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+type WebhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	ResponseStatus *int32     `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Limit      int32                     `json:"limit"`
+	Offset     int32                     `json:"offset"`
+}
+
+func newWebhookResponse(sub db.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:         sub.ID,
+		URL:        sub.URL,
+		EventTypes: sub.EventTypes,
+		CreatedAt:  sub.CreatedAt.Time,
+	}
+}
+
+func newWebhookDeliveryResponse(d db.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:        d.ID,
+		WebhookID: d.SubscriptionID,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		LastError: d.LastError.String,
+		CreatedAt: d.CreatedAt.Time,
+	}
+	if d.Status == WebhookStatusPending {
+		response.NextAttemptAt = &d.NextAttemptAt.Time
+	}
+	if d.ResponseStatus.Valid {
+		response.ResponseStatus = &d.ResponseStatus.Int32
+	}
+	if d.DeliveredAt.Valid {
+		response.DeliveredAt = &d.DeliveredAt.Time
+	}
+	return response
+}
+
+// validateWebhookRequest checks the target URL and event types, defaulting to
+// every event type when none are given.
+func validateWebhookRequest(request *CreateWebhookRequest) error {
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	// Reject obviously internal targets up front; webhookClient enforces the
+	// same rule on whatever the hostname resolves to when delivering.
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("url must point to a public address")
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !isPublicAddr(ip) {
+		return errors.New("url must point to a public address")
+	}
+
+	if len(request.EventTypes) == 0 {
+		request.EventTypes = webhookEventTypes
+		return nil
+	}
+	for _, eventType := range request.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return fmt.Errorf("unsupported event type %q", eventType)
+		}
+	}
+	return nil
+}
+
+func isWebhookEventType(eventType string) bool {
+	for _, known := range webhookEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhook subscribes the caller to events. The signing secret is
+// generated unless one is supplied and is only returned here.
+func (server *Server) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &CreateWebhookRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	err = validateWebhookRequest(request)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	if request.Secret == "" {
+		request.Secret, _, err = newToken()
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	sub, err := server.q.CreateWebhookSubscription(r.Context(), db.CreateWebhookSubscriptionParams{
+		UserID:     callerID(r.Context()),
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: request.EventTypes,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := newWebhookResponse(sub)
+	response.Secret = sub.Secret
+
+	respond.JSON(w, http.StatusCreated, response)
+}
+
+func (server *Server) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	subs, err := server.q.ListWebhookSubscriptionsByUserId(r.Context(), callerID(r.Context()))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListWebhooksResponse{Webhooks: make([]WebhookResponse, 0, len(subs))}
+	for _, sub := range subs {
+		response.Webhooks = append(response.Webhooks, newWebhookResponse(sub))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// DeleteWebhook removes a subscription together with its delivery history.
+func (server *Server) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &WebhookRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeWebhook(w, r, request.WebhookID); !ok {
+		return
+	}
+
+	err = server.q.DeleteWebhookSubscription(r.Context(), request.WebhookID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries pages through a subscription's deliveries, newest
+// first, by ?webhook_id=.
+func (server *Server) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	query := r.URL.Query()
+	webhookID, err := strconv.ParseInt(query.Get("webhook_id"), 10, 64)
+	if err != nil {
+		respond.WriteError(w, http.StatusBadRequest, respond.CodeInvalidRequest, "Invalid webhook_id")
+		return
+	}
+
+	limit, offset, err := parsePagination(query.Get("limit"), query.Get("offset"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeWebhook(w, r, webhookID); !ok {
+		return
+	}
+
+	deliveries, err := server.q.ListWebhookDeliveries(r.Context(), db.ListWebhookDeliveriesParams{
+		SubscriptionID: webhookID,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response := &ListWebhookDeliveriesResponse{
+		Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	for _, d := range deliveries {
+		response.Deliveries = append(response.Deliveries, newWebhookDeliveryResponse(d))
+	}
+
+	respond.JSON(w, http.StatusOK, *response)
+}
+
+// RedeliverWebhook queues a delivery to be sent again right away with a fresh
+// retry budget, whatever its current status.
+func (server *Server) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond.MethodNotAllowed(w)
+		return
+	}
+
+	request := &RedeliverWebhookRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		respond.InvalidBody(w, err)
+		return
+	}
+
+	delivery, err := server.q.GetWebhookDeliveryById(r.Context(), request.DeliveryID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeWebhookDeliveryNotFound, "Delivery not found")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	if _, ok := server.authorizeWebhook(w, r, delivery.SubscriptionID); !ok {
+		return
+	}
+
+	delivery, err = server.q.RedeliverWebhook(r.Context(), db.RedeliverWebhookParams{
+		ID:            delivery.ID,
+		NextAttemptAt: timestamp(time.Now().UTC()),
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	respond.JSON(w, http.StatusAccepted, newWebhookDeliveryResponse(delivery))
+}
+
+// authorizeWebhook loads the subscription and checks that it belongs to the
+// authenticated caller, writing the error response if not.
+func (server *Server) authorizeWebhook(w http.ResponseWriter, r *http.Request, webhookID int64) (db.WebhookSubscription, bool) {
+	sub, err := server.q.GetWebhookSubscriptionById(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			respond.WriteError(w, http.StatusNotFound, codeWebhookNotFound, "Webhook not found")
+			return sub, false
+		}
+		writeError(w, err)
+		return sub, false
+	}
+
+	if sub.UserID != callerID(r.Context()) {
+		respond.WriteError(w, http.StatusForbidden, respond.CodeForbidden, "Webhook does not belong to caller")
+		return sub, false
+	}
+	return sub, true
+}
+
+// enqueueWebhooks schedules envelope for every subscription of userID that
+// listens for its type. Callers pass the queries of the transaction that
+// applies the event, so deliveries exist exactly when the event took effect.
+func enqueueWebhooks(ctx context.Context, q *db.Queries, userID int64, envelope event.Envelope) error {
+	subs, err := q.ListWebhookSubscriptionsForEvent(ctx, db.ListWebhookSubscriptionsForEventParams{
+		UserID:    userID,
+		EventType: envelope.Type,
+	})
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		err := q.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			EventID:        envelope.ID,
+			EventType:      envelope.Type,
+			Payload:        payload,
+			NextAttemptAt:  timestamp(time.Now().UTC()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverWebhooks sends due webhook deliveries. Each attempt that fails is
+// retried with exponential backoff until webhookMaxAttempts, after which the
+// delivery is marked failed and only goes out again if redelivered by hand.
+func (server *Server) DeliverWebhooks() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := server.deliverWebhookBatch()
+		if err != nil {
+			logger.Error("Failed to deliver webhooks",
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func (server *Server) deliverWebhookBatch() error {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	deliveries, err := server.q.ClaimDueWebhookDeliveries(ctx, db.ClaimDueWebhookDeliveriesParams{
+		LeaseUntil: timestamp(now.Add(webhookLease)),
+		Now:        timestamp(now),
+		BatchSize:  webhookBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery db.WebhookDelivery) {
+			defer wg.Done()
+			server.attemptWebhook(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return nil
+}
+
+// attemptWebhook makes one delivery attempt and records its outcome.
+func (server *Server) attemptWebhook(ctx context.Context, delivery db.WebhookDelivery) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.Int64("delivery_id", delivery.ID),
+		slog.String("event_id", delivery.EventID),
+	)
+
+	sub, err := server.q.GetWebhookSubscriptionById(ctx, delivery.SubscriptionID)
+	if err != nil {
+		logger.Error("Failed to load webhook subscription", slog.Any("error", err))
+		return
+	}
+
+	statusCode, err := postWebhook(ctx, sub, delivery)
+	var responseStatus pgtype.Int4
+	if statusCode != 0 {
+		responseStatus = pgtype.Int4{Int32: int32(statusCode), Valid: true}
+	}
+
+	if err == nil {
+		err = server.q.MarkWebhookDelivered(ctx, db.MarkWebhookDeliveredParams{
+			ID:             delivery.ID,
+			ResponseStatus: responseStatus,
+		})
+		if err != nil {
+			logger.Error("Failed to record webhook delivery", slog.Any("error", err))
+		}
+		return
+	}
+
+	attempts := delivery.Attempts + 1
+	status := WebhookStatusPending
+	if attempts >= webhookMaxAttempts {
+		status = WebhookStatusFailed
+	}
+	logger.Warn("Webhook delivery failed",
+		slog.Int("attempt", int(attempts)),
+		slog.String("status", status),
+		slog.Any("error", err),
+	)
+
+	err = server.q.RecordWebhookFailure(ctx, db.RecordWebhookFailureParams{
+		ID:             delivery.ID,
+		Status:         status,
+		ResponseStatus: responseStatus,
+		LastError:      pgtype.Text{String: err.Error(), Valid: true},
+		NextAttemptAt:  timestamp(time.Now().UTC().Add(webhookBackoff(attempts))),
+	})
+	if err != nil {
+		logger.Error("Failed to record webhook failure", slog.Any("error", err))
+	}
+}
+
+// webhookBackoff is the delay before the attempt following attempts failures.
+func webhookBackoff(attempts int32) time.Duration {
+	backoff := webhookInitialBackoff
+	for i := int32(1); i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// postWebhook sends the signed delivery payload to the subscription URL. It
+// returns the response status, if any, and an error unless the receiver
+// answered with a 2xx. The response body is never read back, so the endpoint
+// cannot be used to fetch content on the subscriber's behalf.
+func postWebhook(ctx context.Context, sub db.WebhookSubscription, delivery db.WebhookDelivery) (int, error) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, delivery.EventType)
+	req.Header.Set(webhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhookTimestampHeader, ts)
+	req.Header.Set(webhookSignatureHeader, "sha256="+signWebhook(sub.Secret, ts, delivery.Payload))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// signWebhook signs the timestamp together with the body so a captured request
+// cannot be replayed later with a fresh timestamp.
+func signWebhook(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
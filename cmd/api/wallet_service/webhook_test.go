@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		got := isPublicAddr(netip.MustParseAddr(tt.addr))
+		if got != tt.want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebhookRequestRejectsInternalTargets(t *testing.T) {
+	for _, target := range []string{
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://127.0.0.1/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5/hook",
+		"ftp://example.com/hook",
+	} {
+		err := validateWebhookRequest(&CreateWebhookRequest{URL: target})
+		if err == nil {
+			t.Errorf("validateWebhookRequest(%q) succeeded, want an error", target)
+		}
+	}
+
+	err := validateWebhookRequest(&CreateWebhookRequest{URL: "https://hooks.example.com/wallet"})
+	if err != nil {
+		t.Errorf("public URL rejected: %v", err)
+	}
+}
+
+func TestPostWebhookRefusesLoopback(t *testing.T) {
+	var called bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	sub := db.WebhookSubscription{URL: receiver.URL, Secret: "secret"}
+	delivery := db.WebhookDelivery{ID: 1, EventType: "transaction.mined", Payload: []byte(`{}`)}
+
+	status, err := postWebhook(context.Background(), sub, delivery)
+	if !errors.Is(err, errWebhookDestination) {
+		t.Errorf("error = %v, want errWebhookDestination", err)
+	}
+	if status != 0 || called {
+		t.Errorf("request reached the loopback receiver (status %d)", status)
+	}
+}
@@ -1,17 +1,85 @@
 package config
 
 import (
+	"time"
+
+	"github.com/Dev317/golang_wallet/chain"
+	"github.com/Dev317/golang_wallet/store"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	HTTPServerAddress string `mapstructure:"http_server_address"`
-	DBUser            string `mapstructure:"DB_USER"`
-	DBPassword        string `mapstructure:"DB_PASSWORD"`
-	DBName            string `mapstructure:"DB_NAME"`
-	DBSSLMode         string `mapstructure:"DB_SSL_MODE"`
-	DBHost            string `mapstructure:"DB_HOST"`
-	DBPort            string `mapstructure:"DB_PORT"`
+	HTTPServerAddress string        `mapstructure:"http_server_address"`
+	DBUser            string        `mapstructure:"DB_USER"`
+	DBPassword        string        `mapstructure:"DB_PASSWORD"`
+	DBName            string        `mapstructure:"DB_NAME"`
+	DBSSLMode         string        `mapstructure:"DB_SSL_MODE"`
+	DBHost            string        `mapstructure:"DB_HOST"`
+	DBPort            string        `mapstructure:"DB_PORT"`
+	Confirmations     uint64        `mapstructure:"CONFIRMATIONS"`
+	PollInterval      time.Duration `mapstructure:"POLL_INTERVAL"`
+	DropTimeout       time.Duration `mapstructure:"DROP_TIMEOUT"`
+	ReorgDepth        uint64        `mapstructure:"REORG_DEPTH"`
+	RPCCheckInterval  time.Duration `mapstructure:"RPC_CHECK_INTERVAL"`
+	RPCMaxBlockLag    uint64        `mapstructure:"RPC_MAX_BLOCK_LAG"`
+	RPCMaxErrorRate   float64       `mapstructure:"RPC_MAX_ERROR_RATE"`
+	AdminToken        string        `mapstructure:"ADMIN_TOKEN"`
+}
+
+// Database returns the connection settings for the wallet database.
+func (c Config) Database() store.Config {
+	return store.Config{
+		User:     c.DBUser,
+		Password: c.DBPassword,
+		Name:     c.DBName,
+		SSLMode:  c.DBSSLMode,
+		Host:     c.DBHost,
+		Port:     c.DBPort,
+	}
+}
+
+// RPCOptions returns the health-check settings for the RPC pools.
+func (c Config) RPCOptions() chain.Options {
+	return chain.Options{
+		CheckInterval: c.RPCCheckInterval,
+		MaxBlockLag:   c.RPCMaxBlockLag,
+		MaxErrorRate:  c.RPCMaxErrorRate,
+	}
+}
+
+type ChainItemConfig struct {
+	ChainID   string `mapstructure:"chain_id"`
+	ChainName string `mapstructure:"chain_name"`
+	RPCURL    string `mapstructure:"rpc_url"`
+	// RPCURLs lists fallback endpoints used when RPCURL is unhealthy.
+	RPCURLs []string `mapstructure:"rpc_urls"`
+}
+
+// URLs returns every RPC URL configured for the chain, RPCURL first.
+func (c ChainItemConfig) URLs() []string {
+	urls := []string{}
+	if c.RPCURL != "" {
+		urls = append(urls, c.RPCURL)
+	}
+	for _, url := range c.RPCURLs {
+		if url != c.RPCURL {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+type EthereumConfig struct {
+	ChainItemList []ChainItemConfig `yaml:"ChainItemList,mapstructure"`
+}
+
+// ChainURLs returns the RPC URLs of every configured chain, keyed by chain ID.
+func (c EthereumConfig) ChainURLs() map[string][]string {
+	chainURLs := make(map[string][]string)
+	for _, chainItem := range c.ChainItemList {
+		chainURLs[chainItem.ChainID] = chainItem.URLs()
+	}
+	return chainURLs
 }
 
 type QueueConfig struct {
@@ -34,11 +102,33 @@ func LoadQueueConfig(path string) (config QueueConfig, err error) {
 	return
 }
 
+func LoadEthereumConfig(path string) (config EthereumConfig, err error) {
+	viper.AddConfigPath(path)
+	viper.SetConfigName("ethereum")
+	viper.SetConfigType("yaml")
+
+	err = viper.ReadInConfig()
+	if err != nil {
+		return
+	}
+
+	err = viper.Unmarshal(&config)
+	return
+}
+
 func LoadServerConfig(path string) (config Config, err error) {
 	viper.AddConfigPath(path)
 	viper.SetConfigName("scanner")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("CONFIRMATIONS", 12)
+	viper.SetDefault("POLL_INTERVAL", 5*time.Second)
+	viper.SetDefault("DROP_TIMEOUT", 30*time.Minute)
+	viper.SetDefault("REORG_DEPTH", 64)
+	viper.SetDefault("RPC_CHECK_INTERVAL", 15*time.Second)
+	viper.SetDefault("RPC_MAX_BLOCK_LAG", 5)
+	viper.SetDefault("RPC_MAX_ERROR_RATE", 0.5)
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
@@ -1,27 +1,96 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/Dev317/golang_wallet/chain"
+	"github.com/Dev317/golang_wallet/store"
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	HTTPServerAddress string `mapstructure:"HTTP_SERVER_ADDRESS"`
-	DBUser            string `mapstructure:"DB_USER"`
-	DBPassword        string `mapstructure:"DB_PASSWORD"`
-	DBName            string `mapstructure:"DB_NAME"`
-	DBSSLMode         string `mapstructure:"DB_SSL_MODE"`
-	DBHost            string `mapstructure:"DB_HOST"`
-	DBPort            string `mapstructure:"DB_PORT"`
+	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
+	DBUser               string        `mapstructure:"DB_USER"`
+	DBPassword           string        `mapstructure:"DB_PASSWORD"`
+	DBName               string        `mapstructure:"DB_NAME"`
+	DBSSLMode            string        `mapstructure:"DB_SSL_MODE"`
+	DBHost               string        `mapstructure:"DB_HOST"`
+	DBPort               string        `mapstructure:"DB_PORT"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	RPCCheckInterval     time.Duration `mapstructure:"RPC_CHECK_INTERVAL"`
+	RPCMaxBlockLag       uint64        `mapstructure:"RPC_MAX_BLOCK_LAG"`
+	RPCMaxErrorRate      float64       `mapstructure:"RPC_MAX_ERROR_RATE"`
+	AdminToken           string        `mapstructure:"ADMIN_TOKEN"`
+}
+
+// Database returns the connection settings for the wallet database.
+func (c Config) Database() store.Config {
+	return store.Config{
+		User:     c.DBUser,
+		Password: c.DBPassword,
+		Name:     c.DBName,
+		SSLMode:  c.DBSSLMode,
+		Host:     c.DBHost,
+		Port:     c.DBPort,
+	}
+}
+
+// RPCOptions returns the health-check settings for the RPC pools.
+func (c Config) RPCOptions() chain.Options {
+	return chain.Options{
+		CheckInterval: c.RPCCheckInterval,
+		MaxBlockLag:   c.RPCMaxBlockLag,
+		MaxErrorRate:  c.RPCMaxErrorRate,
+	}
 }
 
 type ChainItemConfig struct {
-	ChainID   string `mapstructure:"chain_id"`
-	ChainName string `mapstructure:"chain_name"`
-	RPCURL    string `mapstructure:"rpc_url"`
+	ChainID   string        `mapstructure:"chain_id"`
+	ChainName string        `mapstructure:"chain_name"`
+	RPCURL    string        `mapstructure:"rpc_url"`
+	Tokens    []TokenConfig `mapstructure:"tokens"`
+	// RPCURLs lists fallback endpoints used when RPCURL is unhealthy.
+	RPCURLs []string `mapstructure:"rpc_urls"`
+	// MulticallAddress overrides the canonical Multicall3 deployment used to
+	// batch balance reads.
+	MulticallAddress string `mapstructure:"multicall_address"`
+}
+
+// URLs returns every RPC URL configured for the chain, RPCURL first.
+func (c ChainItemConfig) URLs() []string {
+	urls := []string{}
+	if c.RPCURL != "" {
+		urls = append(urls, c.RPCURL)
+	}
+	for _, url := range c.RPCURLs {
+		if url != c.RPCURL {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// TokenConfig registers an ERC-20 token so it can be referred to by symbol.
+type TokenConfig struct {
+	Symbol   string `mapstructure:"symbol"`
+	Address  string `mapstructure:"address"`
+	Decimals uint8  `mapstructure:"decimals"`
 }
 
 type EthereumConfig struct {
 	ChainItemList []ChainItemConfig `yaml:"ChainItemList,mapstructure"`
 }
 
+// ChainURLs returns the RPC URLs of every configured chain, keyed by chain ID.
+func (c EthereumConfig) ChainURLs() map[string][]string {
+	chainURLs := make(map[string][]string)
+	for _, chainItem := range c.ChainItemList {
+		chainURLs[chainItem.ChainID] = chainItem.URLs()
+	}
+	return chainURLs
+}
+
 type QueueConifg struct {
 	URI string `mapstructure:"URI"`
 }
@@ -61,6 +130,12 @@ func LoadServerConfig(path string) (config Config, err error) {
 	viper.SetConfigName("wallet")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("ACCESS_TOKEN_DURATION", 15*time.Minute)
+	viper.SetDefault("REFRESH_TOKEN_DURATION", 24*time.Hour)
+	viper.SetDefault("RPC_CHECK_INTERVAL", 15*time.Second)
+	viper.SetDefault("RPC_MAX_BLOCK_LAG", 5)
+	viper.SetDefault("RPC_MAX_ERROR_RATE", 0.5)
+
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
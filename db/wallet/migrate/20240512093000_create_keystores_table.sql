@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE keystores (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    crypto JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX keystores_account_id_index ON keystores (account_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS keystores;
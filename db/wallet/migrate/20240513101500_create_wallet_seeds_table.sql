@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE wallet_seeds (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    crypto JSONB NOT NULL,
+    next_index INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX wallet_seeds_user_id_index ON wallet_seeds (user_id);
+
+ALTER TABLE accounts ADD COLUMN derivation_index INT;
+
+CREATE UNIQUE INDEX accounts_user_id_derivation_index_chain_id_index ON accounts (user_id, derivation_index, chain_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS accounts_user_id_derivation_index_chain_id_index;
+ALTER TABLE accounts DROP COLUMN IF EXISTS derivation_index;
+DROP TABLE IF EXISTS wallet_seeds;
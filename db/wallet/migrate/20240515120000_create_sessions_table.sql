@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    access_token_hash VARCHAR NOT NULL,
+    refresh_token_hash VARCHAR NOT NULL,
+    access_expires_at TIMESTAMP NOT NULL,
+    refresh_expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX sessions_access_token_hash_index ON sessions (access_token_hash);
+CREATE UNIQUE INDEX sessions_refresh_token_hash_index ON sessions (refresh_token_hash);
+CREATE INDEX sessions_user_id_index ON sessions (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS sessions;
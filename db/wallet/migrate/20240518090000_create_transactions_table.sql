@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE transactions (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    chain_id INT NOT NULL,
+    from_address VARCHAR NOT NULL,
+    to_address VARCHAR NOT NULL,
+    value NUMERIC NOT NULL,
+    nonce BIGINT,
+    gas_limit BIGINT,
+    gas_price NUMERIC,
+    hash VARCHAR,
+    status VARCHAR NOT NULL DEFAULT 'created',
+    error VARCHAR,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE,
+    CONSTRAINT transactions_status_check CHECK (
+        status IN ('created', 'signed', 'broadcast', 'mined', 'confirmed', 'failed', 'dropped')
+    )
+);
+
+CREATE INDEX transactions_account_id_index ON transactions (account_id, id DESC);
+CREATE UNIQUE INDEX transactions_hash_index ON transactions (hash);
+CREATE INDEX transactions_status_index ON transactions (status);
+
+-- +goose Down
+DROP TABLE IF EXISTS transactions;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE scan_cursors (
+    chain_id INT PRIMARY KEY,
+    block_number BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS scan_cursors;
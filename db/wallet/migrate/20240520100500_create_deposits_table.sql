@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE deposits (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    chain_id INT NOT NULL,
+    tx_hash VARCHAR NOT NULL,
+    from_address VARCHAR NOT NULL,
+    to_address VARCHAR NOT NULL,
+    value NUMERIC NOT NULL,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'detected',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE,
+    CONSTRAINT deposits_status_check CHECK (status IN ('detected', 'confirmed'))
+);
+
+CREATE UNIQUE INDEX deposits_chain_id_tx_hash_index ON deposits (chain_id, tx_hash);
+CREATE INDEX deposits_account_id_index ON deposits (account_id);
+CREATE INDEX deposits_chain_id_status_block_number_index ON deposits (chain_id, status, block_number);
+
+-- +goose Down
+DROP TABLE IF EXISTS deposits;
@@ -0,0 +1,31 @@
+-- +goose Up
+ALTER TABLE scan_cursors ADD COLUMN block_hash VARCHAR NOT NULL DEFAULT '';
+
+CREATE TABLE scanned_blocks (
+    chain_id INT NOT NULL,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR NOT NULL,
+    parent_hash VARCHAR NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chain_id, block_number)
+);
+
+ALTER TABLE deposits DROP CONSTRAINT deposits_status_check;
+ALTER TABLE deposits ADD CONSTRAINT deposits_status_check CHECK (status IN ('detected', 'confirmed', 'reverted'));
+
+ALTER TABLE transactions ADD COLUMN block_number BIGINT;
+ALTER TABLE transactions ADD COLUMN block_hash VARCHAR;
+
+CREATE INDEX transactions_chain_id_block_number_index ON transactions (chain_id, block_number);
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_chain_id_block_number_index;
+ALTER TABLE transactions DROP COLUMN IF EXISTS block_hash;
+ALTER TABLE transactions DROP COLUMN IF EXISTS block_number;
+
+DELETE FROM deposits WHERE status = 'reverted';
+ALTER TABLE deposits DROP CONSTRAINT deposits_status_check;
+ALTER TABLE deposits ADD CONSTRAINT deposits_status_check CHECK (status IN ('detected', 'confirmed'));
+
+DROP TABLE IF EXISTS scanned_blocks;
+ALTER TABLE scan_cursors DROP COLUMN IF EXISTS block_hash;
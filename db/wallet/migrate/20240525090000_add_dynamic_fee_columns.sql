@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN tx_type INT NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN max_fee_per_gas NUMERIC;
+ALTER TABLE transactions ADD COLUMN max_priority_fee_per_gas NUMERIC;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN IF EXISTS max_priority_fee_per_gas;
+ALTER TABLE transactions DROP COLUMN IF EXISTS max_fee_per_gas;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tx_type;
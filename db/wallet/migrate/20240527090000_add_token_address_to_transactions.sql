@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN token_address TEXT;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN IF EXISTS token_address;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE account_nonces (
+    chain_id INT NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    next_nonce BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chain_id, address)
+);
+
+CREATE TABLE released_nonces (
+    chain_id INT NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    nonce BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chain_id, address, nonce)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS released_nonces;
+DROP TABLE IF EXISTS account_nonces;
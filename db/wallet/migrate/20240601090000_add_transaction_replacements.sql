@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN replaces_id BIGINT REFERENCES transactions(id);
+CREATE INDEX transactions_sender_nonce_idx ON transactions (chain_id, from_address, nonce);
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_sender_nonce_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS replaces_id;
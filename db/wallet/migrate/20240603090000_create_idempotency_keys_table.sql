@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE idempotency_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR NOT NULL,
+    response_status INT,
+    response_content_type VARCHAR,
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idempotency_keys_user_id_key_index ON idempotency_keys (user_id, idempotency_key);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE processed_events (
+    consumer VARCHAR(255) NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS processed_events;
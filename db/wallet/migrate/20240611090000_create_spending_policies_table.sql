@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE spending_policies (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    account_id BIGINT,
+    chain_id INT,
+    kind VARCHAR(32) NOT NULL,
+    token_address TEXT,
+    max_amount NUMERIC,
+    window_seconds INT,
+    addresses TEXT[],
+    start_minute INT,
+    end_minute INT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX spending_policies_user_id_index ON spending_policies (user_id);
+
+CREATE INDEX transactions_account_id_created_at_index ON transactions (account_id, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_account_id_created_at_index;
+DROP TABLE IF EXISTS spending_policies;
@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE approval_rules (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    token_address TEXT,
+    threshold NUMERIC NOT NULL,
+    required_approvals INT NOT NULL,
+    approver_ids BIGINT[] NOT NULL,
+    ttl_seconds INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE
+);
+
+-- One rule per asset of an account; token_address is NULL for the native coin.
+CREATE UNIQUE INDEX approval_rules_account_asset_index ON approval_rules (account_id, COALESCE(token_address, ''));
+
+CREATE TABLE approval_requests (
+    transaction_id BIGINT PRIMARY KEY,
+    rule_id BIGINT,
+    required_approvals INT NOT NULL,
+    approver_ids BIGINT[] NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_transaction_id FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE,
+    CONSTRAINT fk_rule_id FOREIGN KEY (rule_id) REFERENCES approval_rules (id) ON DELETE SET NULL
+);
+
+CREATE INDEX approval_requests_expires_at_index ON approval_requests (expires_at);
+
+CREATE TABLE transaction_approvals (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    decision VARCHAR(16) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_transaction_id FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT transaction_approvals_decision_check CHECK (decision IN ('approve', 'reject')),
+    CONSTRAINT transaction_approvals_unique UNIQUE (transaction_id, user_id)
+);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (
+    status IN ('pending_approval', 'approved', 'rejected', 'expired', 'created', 'signed', 'broadcast', 'mined', 'confirmed', 'failed', 'dropped', 'replaced')
+);
+
+-- +goose Down
+ALTER TABLE transactions DROP CONSTRAINT transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (
+    status IN ('created', 'signed', 'broadcast', 'mined', 'confirmed', 'failed', 'dropped', 'replaced')
+);
+DROP TABLE IF EXISTS transaction_approvals;
+DROP TABLE IF EXISTS approval_requests;
+DROP TABLE IF EXISTS approval_rules;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE contacts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    chain_id INT NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    address VARCHAR NOT NULL,
+    -- The contact cannot be sent to before this time.
+    usable_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX contacts_user_chain_address_index ON contacts (user_id, chain_id, lower(address));
+CREATE UNIQUE INDEX contacts_user_label_index ON contacts (user_id, lower(label));
+
+-- +goose Down
+DROP TABLE IF EXISTS contacts;
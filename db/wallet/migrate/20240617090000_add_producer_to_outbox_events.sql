@@ -0,0 +1,12 @@
+-- +goose Up
+-- Both services write to the outbox; each relay only publishes its own rows.
+ALTER TABLE outbox_events ADD COLUMN producer VARCHAR(64) NOT NULL DEFAULT 'wallet_service';
+ALTER TABLE outbox_events ALTER COLUMN producer DROP DEFAULT;
+
+DROP INDEX IF EXISTS outbox_events_pending_index;
+CREATE INDEX outbox_events_pending_index ON outbox_events (producer, id) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS outbox_events_pending_index;
+CREATE INDEX outbox_events_pending_index ON outbox_events (id) WHERE published_at IS NULL;
+ALTER TABLE outbox_events DROP COLUMN IF EXISTS producer;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Transactions the scanner is watching, so tracking survives a restart.
+CREATE TABLE tracked_transactions (
+    transaction_id BIGINT PRIMARY KEY,
+    chain_id INT NOT NULL,
+    tx_hash VARCHAR NOT NULL,
+    from_address VARCHAR NOT NULL,
+    to_address VARCHAR NOT NULL,
+    nonce BIGINT NOT NULL,
+    correlation_id VARCHAR(255) NOT NULL,
+    mined_block_hash VARCHAR,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP,
+    CONSTRAINT fk_transaction_id FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE
+);
+
+CREATE INDEX tracked_transactions_active_index ON tracked_transactions (transaction_id) WHERE finished_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS tracked_transactions;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Replacements mark the original transaction 'replaced'. Databases migrated
+-- before the approval tables were added reject that status, so the check is
+-- redefined here with every status the wallet writes.
+ALTER TABLE transactions DROP CONSTRAINT transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check CHECK (
+    status IN ('pending_approval', 'approved', 'rejected', 'expired', 'created', 'signed', 'broadcast', 'mined', 'confirmed', 'failed', 'dropped', 'replaced')
+);
+
+-- +goose Down
+-- The check is owned by 20240613090000_create_approval_tables, whose Down
+-- restores the earlier statuses.
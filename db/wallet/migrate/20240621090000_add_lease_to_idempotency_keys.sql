@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc');
+ALTER TABLE idempotency_keys ADD COLUMN completed_at TIMESTAMP;
+UPDATE idempotency_keys SET completed_at = updated_at WHERE response_status IS NOT NULL;
+CREATE INDEX idempotency_keys_completed_at_index ON idempotency_keys (completed_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idempotency_keys_completed_at_index;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
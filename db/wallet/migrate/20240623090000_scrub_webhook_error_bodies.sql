@@ -0,0 +1,7 @@
+-- +goose Up
+-- Failed deliveries used to record the start of the receiver's response body.
+UPDATE webhook_deliveries
+SET last_error = substring(last_error FROM '^receiver responded [0-9]+')
+WHERE last_error ~ '^receiver responded [0-9]+: ';
+
+-- +goose Down
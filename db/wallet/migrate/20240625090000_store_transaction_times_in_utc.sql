@@ -0,0 +1,17 @@
+-- +goose Up
+-- Transaction times were written with CURRENT_TIMESTAMP, i.e. as wall-clock
+-- time in the session's time zone, while spending windows are computed in
+-- UTC. Existing rows are converted assuming this migration runs with the same
+-- TimeZone setting the service used.
+UPDATE transactions
+SET created_at = (created_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'utc',
+    updated_at = (updated_at AT TIME ZONE current_setting('TimeZone')) AT TIME ZONE 'utc';
+ALTER TABLE transactions ALTER COLUMN created_at SET DEFAULT (now() AT TIME ZONE 'utc');
+ALTER TABLE transactions ALTER COLUMN updated_at SET DEFAULT (now() AT TIME ZONE 'utc');
+
+-- +goose Down
+ALTER TABLE transactions ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE transactions ALTER COLUMN created_at SET DEFAULT CURRENT_TIMESTAMP;
+UPDATE transactions
+SET created_at = (created_at AT TIME ZONE 'utc') AT TIME ZONE current_setting('TimeZone'),
+    updated_at = (updated_at AT TIME ZONE 'utc') AT TIME ZONE current_setting('TimeZone');
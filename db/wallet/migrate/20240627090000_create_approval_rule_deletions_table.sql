@@ -0,0 +1,15 @@
+-- +goose Up
+-- A request by an account owner to delete an approval rule. The rule is only
+-- deleted once required_approvals of its approvers agreed.
+CREATE TABLE approval_rule_deletions (
+    rule_id BIGINT PRIMARY KEY,
+    requested_by BIGINT NOT NULL,
+    approved_by BIGINT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_rule_id FOREIGN KEY (rule_id) REFERENCES approval_rules (id) ON DELETE CASCADE,
+    CONSTRAINT fk_requested_by FOREIGN KEY (requested_by) REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS approval_rule_deletions;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- ERC-20 deposits are read from Transfer logs, several of which can share a
+-- transaction, so deposits are keyed by log index. Native transfers have no
+-- log and use -1.
+ALTER TABLE deposits ADD COLUMN token_address VARCHAR;
+ALTER TABLE deposits ADD COLUMN log_index INT NOT NULL DEFAULT -1;
+DROP INDEX deposits_chain_id_tx_hash_index;
+CREATE UNIQUE INDEX deposits_chain_id_tx_hash_log_index_index ON deposits (chain_id, tx_hash, log_index);
+
+-- +goose Down
+DELETE FROM deposits WHERE log_index <> -1;
+DROP INDEX IF EXISTS deposits_chain_id_tx_hash_log_index_index;
+CREATE UNIQUE INDEX deposits_chain_id_tx_hash_index ON deposits (chain_id, tx_hash);
+ALTER TABLE deposits DROP COLUMN IF EXISTS log_index;
+ALTER TABLE deposits DROP COLUMN IF EXISTS token_address;
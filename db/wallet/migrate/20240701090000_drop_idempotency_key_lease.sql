@@ -0,0 +1,6 @@
+-- +goose Up
+-- An in-progress key is never taken over, so it needs no lease.
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS locked_until;
+
+-- +goose Down
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP NOT NULL DEFAULT (now() AT TIME ZONE 'utc');
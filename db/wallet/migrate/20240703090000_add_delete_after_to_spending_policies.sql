@@ -0,0 +1,10 @@
+-- +goose Up
+-- A deleted policy stays in force until delete_after, giving the owner time
+-- to notice and cancel a deletion they did not ask for.
+ALTER TABLE spending_policies ADD COLUMN delete_after TIMESTAMP;
+
+CREATE INDEX spending_policies_delete_after_index ON spending_policies (delete_after) WHERE delete_after IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS spending_policies_delete_after_index;
+ALTER TABLE spending_policies DROP COLUMN IF EXISTS delete_after;
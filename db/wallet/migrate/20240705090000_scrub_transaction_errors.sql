@@ -0,0 +1,15 @@
+-- +goose Up
+-- Transactions used to record the raw error they ended with, node and
+-- internal detail included. Keep only the fixed message for each kind.
+UPDATE transactions
+SET error = CASE
+    WHEN error LIKE 'transaction reverted on-chain%' THEN 'Transaction reverted on-chain'
+    WHEN error LIKE 'transaction dropped from the network%' THEN 'Transaction dropped from the network'
+    WHEN error LIKE 'transaction replaced%' THEN 'Transaction replaced by another with the same nonce'
+    WHEN error = 'rejected by approvers' THEN 'Rejected by approvers'
+    ELSE 'Transaction could not be sent'
+END
+WHERE error IS NOT NULL
+  AND error <> 'approval request expired';
+
+-- +goose Down
@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
-  user_id, address, chain_id
+  user_id, address, chain_id, derivation_index
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
 RETURNING *;
 
@@ -14,3 +14,14 @@ SELECT * FROM accounts WHERE address = $1 AND chain_id = $2 LIMIT 1;
 
 -- name: GetAccountAddressById :one
 SELECT address FROM accounts WHERE id = $1 LIMIT 1;
+
+-- name: GetAccountById :one
+SELECT * FROM accounts WHERE id = $1 LIMIT 1;
+
+-- name: UpdateAccountBalance :exec
+UPDATE accounts
+SET balance = $3, updated_at = CURRENT_TIMESTAMP
+WHERE address = $1 AND chain_id = $2;
+
+-- name: ListAccountsByAddresses :many
+SELECT * FROM accounts WHERE chain_id = sqlc.arg(chain_id) AND address = ANY(sqlc.arg(addresses)::VARCHAR[]);
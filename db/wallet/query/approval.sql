@@ -0,0 +1,108 @@
+-- name: CreateApprovalRule :one
+INSERT INTO approval_rules (
+  account_id, token_address, threshold, required_approvals, approver_ids, ttl_seconds
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetApprovalRuleById :one
+SELECT * FROM approval_rules
+WHERE id = $1 LIMIT 1;
+
+-- name: GetApprovalRuleForAsset :one
+SELECT * FROM approval_rules
+WHERE account_id = sqlc.arg(account_id)
+  AND token_address IS NOT DISTINCT FROM sqlc.narg(token_address)
+LIMIT 1;
+
+-- name: ListApprovalRulesByAccountId :many
+SELECT * FROM approval_rules
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: DeleteApprovalRule :exec
+DELETE FROM approval_rules
+WHERE id = $1;
+
+-- name: LockApprovalRule :one
+SELECT * FROM approval_rules
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: CreateApprovalRuleDeletion :one
+INSERT INTO approval_rule_deletions (
+  rule_id, requested_by, expires_at
+) VALUES (
+  sqlc.arg(rule_id), sqlc.arg(requested_by), sqlc.arg(expires_at)
+)
+ON CONFLICT (rule_id) DO UPDATE
+SET requested_by = EXCLUDED.requested_by,
+    approved_by = '{}',
+    expires_at = EXCLUDED.expires_at,
+    created_at = CURRENT_TIMESTAMP
+WHERE approval_rule_deletions.expires_at <= sqlc.arg(now)
+RETURNING *;
+
+-- name: GetApprovalRuleDeletion :one
+SELECT * FROM approval_rule_deletions
+WHERE rule_id = $1 LIMIT 1;
+
+-- name: SetApprovalRuleDeletionApprovals :one
+UPDATE approval_rule_deletions
+SET approved_by = $2
+WHERE rule_id = $1
+RETURNING *;
+
+-- name: CreateApprovalRequest :one
+INSERT INTO approval_requests (
+  transaction_id, rule_id, required_approvals, approver_ids, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetApprovalRequest :one
+SELECT * FROM approval_requests
+WHERE transaction_id = $1 LIMIT 1;
+
+-- name: LockTransactionForApproval :one
+SELECT * FROM transactions
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: RecordApprovalDecision :one
+INSERT INTO transaction_approvals (
+  transaction_id, user_id, decision, comment
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (transaction_id, user_id) DO NOTHING
+RETURNING *;
+
+-- name: ListTransactionApprovals :many
+SELECT * FROM transaction_approvals
+WHERE transaction_id = $1
+ORDER BY id;
+
+-- name: ListPendingApprovalsForApprover :many
+SELECT t.* FROM transactions t
+JOIN approval_requests r ON r.transaction_id = t.id
+WHERE t.status = 'pending_approval'
+  AND sqlc.arg(user_id)::bigint = ANY(r.approver_ids)
+  AND NOT EXISTS (
+    SELECT 1 FROM transaction_approvals a
+    WHERE a.transaction_id = t.id AND a.user_id = sqlc.arg(user_id)
+  )
+ORDER BY t.id;
+
+-- name: ExpireApprovalRequests :many
+UPDATE transactions t
+SET status = 'expired',
+    error = 'approval request expired',
+    updated_at = (now() AT TIME ZONE 'utc')
+FROM approval_requests r
+WHERE r.transaction_id = t.id
+  AND t.status IN ('pending_approval', 'approved')
+  AND r.expires_at <= sqlc.arg(now)
+RETURNING t.*;
@@ -0,0 +1,27 @@
+-- name: CreateContact :one
+INSERT INTO contacts (
+  user_id, chain_id, label, address, usable_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetContactById :one
+SELECT * FROM contacts
+WHERE id = $1 LIMIT 1;
+
+-- name: ListContactsByUserId :many
+SELECT * FROM contacts
+WHERE user_id = $1
+ORDER BY lower(label);
+
+-- name: UpdateContactLabel :one
+UPDATE contacts
+SET label = $2,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteContact :exec
+DELETE FROM contacts
+WHERE id = $1;
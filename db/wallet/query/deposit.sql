@@ -0,0 +1,30 @@
+-- name: CreateDeposit :one
+INSERT INTO deposits (
+  account_id, chain_id, tx_hash, from_address, to_address, value, block_number, block_hash, token_address, log_index
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+ON CONFLICT (chain_id, tx_hash, log_index) DO UPDATE
+SET status = 'detected',
+    block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    updated_at = CURRENT_TIMESTAMP
+WHERE deposits.status = 'reverted'
+RETURNING *;
+
+-- name: ListDepositsToConfirm :many
+SELECT * FROM deposits
+WHERE chain_id = $1 AND status = 'detected' AND block_number <= $2
+ORDER BY block_number;
+
+-- name: MarkDepositConfirmed :one
+UPDATE deposits
+SET status = 'confirmed', updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'detected'
+RETURNING *;
+
+-- name: RevertDepositsAbove :many
+UPDATE deposits
+SET status = 'reverted', updated_at = CURRENT_TIMESTAMP
+WHERE chain_id = $1 AND block_number > $2 AND status IN ('detected', 'confirmed')
+RETURNING *;
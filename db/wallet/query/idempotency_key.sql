@@ -0,0 +1,26 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  user_id, idempotency_key, request_hash
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2
+LIMIT 1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response_status = $2,
+    response_content_type = $3,
+    response_body = $4,
+    completed_at = $5,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE completed_at < $1;
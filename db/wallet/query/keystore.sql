@@ -0,0 +1,10 @@
+-- name: CreateKeystore :one
+INSERT INTO keystores (
+  account_id, crypto
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetKeystoreByAccountId :one
+SELECT * FROM keystores WHERE account_id = $1 LIMIT 1;
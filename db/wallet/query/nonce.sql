@@ -0,0 +1,40 @@
+-- name: CreateAccountNonce :exec
+INSERT INTO account_nonces (
+  chain_id, address, next_nonce
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (chain_id, address) DO NOTHING;
+
+-- name: LockAccountNonce :one
+SELECT * FROM account_nonces
+WHERE chain_id = $1 AND address = $2
+FOR UPDATE;
+
+-- name: ListAccountNonces :many
+SELECT * FROM account_nonces;
+
+-- name: SetAccountNextNonce :exec
+UPDATE account_nonces
+SET next_nonce = $3, updated_at = CURRENT_TIMESTAMP
+WHERE chain_id = $1 AND address = $2;
+
+-- name: ReleaseNonce :exec
+INSERT INTO released_nonces (
+  chain_id, address, nonce
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT DO NOTHING;
+
+-- name: TakeReleasedNonce :one
+DELETE FROM released_nonces
+WHERE chain_id = $1 AND address = $2 AND nonce = (
+  SELECT MIN(nonce) FROM released_nonces
+  WHERE chain_id = $1 AND address = $2
+)
+RETURNING nonce;
+
+-- name: PruneReleasedNonces :exec
+DELETE FROM released_nonces
+WHERE chain_id = $1 AND address = $2 AND nonce < $3;
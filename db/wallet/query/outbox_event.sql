@@ -0,0 +1,24 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+  producer, queue_name, payload
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListPendingOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE producer = $1 AND published_at IS NULL
+ORDER BY id
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+WHERE id = $1;
+
+-- name: RecordOutboxEventFailure :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
@@ -0,0 +1,8 @@
+-- name: MarkEventProcessed :one
+INSERT INTO processed_events (
+  consumer, event_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (consumer, event_id) DO NOTHING
+RETURNING event_id;
@@ -0,0 +1,13 @@
+-- name: GetScanCursor :one
+SELECT * FROM scan_cursors WHERE chain_id = $1 LIMIT 1;
+
+-- name: UpsertScanCursor :exec
+INSERT INTO scan_cursors (
+  chain_id, block_number, block_hash
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (chain_id) DO UPDATE
+SET block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    updated_at = CURRENT_TIMESTAMP;
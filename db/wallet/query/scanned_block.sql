@@ -0,0 +1,17 @@
+-- name: CreateScannedBlock :exec
+INSERT INTO scanned_blocks (
+  chain_id, block_number, block_hash, parent_hash
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (chain_id, block_number) DO UPDATE
+SET block_hash = EXCLUDED.block_hash, parent_hash = EXCLUDED.parent_hash;
+
+-- name: GetScannedBlock :one
+SELECT * FROM scanned_blocks WHERE chain_id = $1 AND block_number = $2 LIMIT 1;
+
+-- name: DeleteScannedBlocksAbove :exec
+DELETE FROM scanned_blocks WHERE chain_id = $1 AND block_number > $2;
+
+-- name: PruneScannedBlocks :exec
+DELETE FROM scanned_blocks WHERE chain_id = $1 AND block_number < $2;
@@ -0,0 +1,26 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+  user_id, access_token_hash, refresh_token_hash, access_expires_at, refresh_expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetSessionByAccessTokenHash :one
+SELECT * FROM sessions WHERE access_token_hash = $1 LIMIT 1;
+
+-- name: GetSessionByRefreshTokenHash :one
+SELECT * FROM sessions WHERE refresh_token_hash = $1 LIMIT 1;
+
+-- name: RotateSession :one
+UPDATE sessions
+SET access_token_hash = sqlc.arg(access_token_hash),
+    refresh_token_hash = sqlc.arg(refresh_token_hash),
+    access_expires_at = sqlc.arg(access_expires_at),
+    refresh_expires_at = sqlc.arg(refresh_expires_at),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg(id) AND refresh_token_hash = sqlc.arg(old_refresh_token_hash)
+RETURNING *;
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE id = $1;
@@ -0,0 +1,62 @@
+-- name: CreateSpendingPolicy :one
+INSERT INTO spending_policies (
+  user_id, account_id, chain_id, kind, token_address, max_amount, window_seconds, addresses, start_minute, end_minute
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+RETURNING *;
+
+-- name: GetSpendingPolicyById :one
+SELECT * FROM spending_policies
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSpendingPoliciesByUserId :many
+SELECT * FROM spending_policies
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: ListSpendingPoliciesForAccount :many
+SELECT * FROM spending_policies
+WHERE user_id = sqlc.arg(user_id)
+  AND (account_id IS NULL OR account_id = sqlc.arg(account_id))
+  AND (chain_id IS NULL OR chain_id = sqlc.arg(chain_id))
+  AND (delete_after IS NULL OR delete_after > sqlc.arg(now))
+ORDER BY id;
+
+-- name: ScheduleSpendingPolicyDeletion :one
+UPDATE spending_policies
+SET delete_after = COALESCE(delete_after, sqlc.arg(delete_after)),
+    updated_at = (now() AT TIME ZONE 'utc')
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CancelSpendingPolicyDeletion :one
+UPDATE spending_policies
+SET delete_after = NULL,
+    updated_at = (now() AT TIME ZONE 'utc')
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteDueSpendingPolicies :exec
+DELETE FROM spending_policies
+WHERE delete_after <= $1;
+
+-- name: LockUserSpending :exec
+SELECT pg_advisory_xact_lock(sqlc.arg(namespace)::int, (sqlc.arg(user_id)::bigint % 2147483648)::int);
+
+-- name: SumOutgoingValue :one
+SELECT COALESCE(SUM(t.value), 0)::numeric AS total
+FROM transactions t
+JOIN accounts a ON a.id = t.account_id
+WHERE a.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(account_id)::bigint IS NULL OR t.account_id = sqlc.narg(account_id))
+  AND t.chain_id = sqlc.arg(chain_id)
+  AND lower(t.token_address) IS NOT DISTINCT FROM sqlc.narg(token_address)
+  AND t.created_at >= sqlc.arg(since)
+  AND t.status NOT IN ('failed', 'dropped', 'rejected', 'expired', 'replaced')
+  -- A replacement counts instead of its original only once it won; until
+  -- then the original is the one counted.
+  AND (t.replaces_id IS NULL OR EXISTS (
+    SELECT 1 FROM transactions o
+    WHERE o.id = t.replaces_id AND o.status = 'replaced'
+  ));
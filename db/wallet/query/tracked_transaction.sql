@@ -0,0 +1,37 @@
+-- name: StartTrackingTransaction :exec
+INSERT INTO tracked_transactions (
+  transaction_id, chain_id, tx_hash, from_address, to_address, nonce, correlation_id, started_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (transaction_id) DO NOTHING;
+
+-- name: RestartTrackingTransaction :exec
+INSERT INTO tracked_transactions (
+  transaction_id, chain_id, tx_hash, from_address, to_address, nonce, correlation_id, started_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (transaction_id) DO UPDATE
+SET mined_block_hash = NULL, finished_at = NULL, started_at = EXCLUDED.started_at;
+
+-- name: ListActiveTrackedTransactions :many
+SELECT * FROM tracked_transactions
+WHERE finished_at IS NULL
+ORDER BY transaction_id;
+
+-- name: ListUntrackedInFlightTransactions :many
+SELECT t.* FROM transactions t
+LEFT JOIN tracked_transactions tt ON tt.transaction_id = t.id
+WHERE t.status IN ('signed', 'broadcast', 'mined') AND t.hash IS NOT NULL AND tt.transaction_id IS NULL
+ORDER BY t.id;
+
+-- name: SetTrackedTransactionMinedBlock :exec
+UPDATE tracked_transactions
+SET mined_block_hash = $2
+WHERE transaction_id = $1;
+
+-- name: FinishTrackedTransaction :exec
+UPDATE tracked_transactions
+SET finished_at = $2
+WHERE transaction_id = $1;
@@ -0,0 +1,78 @@
+-- name: CreateTransaction :one
+INSERT INTO transactions (
+  account_id, chain_id, from_address, to_address, value, token_address
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: CreateReplacementTransaction :one
+INSERT INTO transactions (
+  account_id, chain_id, from_address, to_address, value, token_address, nonce, replaces_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetLiveReplacement :one
+SELECT * FROM transactions
+WHERE replaces_id = $1 AND status IN ('created', 'signed', 'broadcast')
+LIMIT 1;
+
+-- name: ListTransactionsByNonce :many
+SELECT * FROM transactions
+WHERE chain_id = $1 AND from_address = $2 AND nonce = $3;
+
+-- name: GetTransactionById :one
+SELECT * FROM transactions WHERE id = $1 LIMIT 1;
+
+-- name: LockTransaction :one
+SELECT * FROM transactions
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetTransactionByHash :one
+SELECT * FROM transactions WHERE hash = $1 LIMIT 1;
+
+-- name: ListTransactionsByAccountId :many
+SELECT * FROM transactions
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: MarkTransactionSigned :one
+UPDATE transactions
+SET nonce = $2,
+    gas_limit = $3,
+    gas_price = $4,
+    hash = $5,
+    tx_type = $6,
+    max_fee_per_gas = $7,
+    max_priority_fee_per_gas = $8,
+    status = 'signed',
+    updated_at = (now() AT TIME ZONE 'utc')
+WHERE id = $1 AND status IN ('created', 'approved')
+RETURNING *;
+
+-- name: UpdateTransactionStatus :one
+UPDATE transactions
+SET status = sqlc.arg(to_status),
+    error = sqlc.narg(error),
+    updated_at = (now() AT TIME ZONE 'utc')
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: SetTransactionBlock :exec
+UPDATE transactions
+SET block_number = $2, block_hash = $3, updated_at = (now() AT TIME ZONE 'utc')
+WHERE id = $1;
+
+-- name: ListTransactionsInBlocksAbove :many
+SELECT * FROM transactions
+WHERE chain_id = $1 AND block_number > $2 AND status IN ('mined', 'confirmed');
+
+-- name: ListInFlightNonces :many
+SELECT nonce FROM transactions
+WHERE chain_id = $1 AND from_address = $2 AND nonce >= $3
+  AND status IN ('signed', 'broadcast', 'mined', 'confirmed');
@@ -0,0 +1,16 @@
+-- name: CreateWalletSeed :one
+INSERT INTO wallet_seeds (
+  user_id, crypto
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetWalletSeedByUserId :one
+SELECT * FROM wallet_seeds WHERE user_id = $1 LIMIT 1;
+
+-- name: ReserveDerivationIndex :one
+UPDATE wallet_seeds
+SET next_index = next_index + 1, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+RETURNING (next_index - 1)::INT AS derivation_index;
@@ -7,25 +7,33 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
-  user_id, address, chain_id
+  user_id, address, chain_id, derivation_index
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 )
-RETURNING id, user_id, address, chain_id, balance, created_at, updated_at
+RETURNING id, user_id, address, chain_id, balance, created_at, updated_at, derivation_index
 `
 
 type CreateAccountParams struct {
-	UserID  int64  `json:"user_id"`
-	Address string `json:"address"`
-	ChainID int32  `json:"chain_id"`
+	UserID          int64       `json:"user_id"`
+	Address         string      `json:"address"`
+	ChainID         int32       `json:"chain_id"`
+	DerivationIndex pgtype.Int4 `json:"derivation_index"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.UserID, arg.Address, arg.ChainID)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.UserID,
+		arg.Address,
+		arg.ChainID,
+		arg.DerivationIndex,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -35,6 +43,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DerivationIndex,
 	)
 	return i, err
 }
@@ -51,7 +60,7 @@ func (q *Queries) GetAccountAddressById(ctx context.Context, id int64) (string,
 }
 
 const getAccountByAddressAndByChainId = `-- name: GetAccountByAddressAndByChainId :one
-SELECT id, user_id, address, chain_id, balance, created_at, updated_at FROM accounts WHERE address = $1 AND chain_id = $2 LIMIT 1
+SELECT id, user_id, address, chain_id, balance, created_at, updated_at, derivation_index FROM accounts WHERE address = $1 AND chain_id = $2 LIMIT 1
 `
 
 type GetAccountByAddressAndByChainIdParams struct {
@@ -70,12 +79,33 @@ func (q *Queries) GetAccountByAddressAndByChainId(ctx context.Context, arg GetAc
 		&i.Balance,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DerivationIndex,
+	)
+	return i, err
+}
+
+const getAccountById = `-- name: GetAccountById :one
+SELECT id, user_id, address, chain_id, balance, created_at, updated_at, derivation_index FROM accounts WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountById(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountById, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Address,
+		&i.ChainID,
+		&i.Balance,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DerivationIndex,
 	)
 	return i, err
 }
 
 const getAccountByUserId = `-- name: GetAccountByUserId :many
-SELECT id, user_id, address, chain_id, balance, created_at, updated_at FROM accounts WHERE user_id = $1
+SELECT id, user_id, address, chain_id, balance, created_at, updated_at, derivation_index FROM accounts WHERE user_id = $1
 `
 
 func (q *Queries) GetAccountByUserId(ctx context.Context, userID int64) ([]Account, error) {
@@ -95,6 +125,7 @@ func (q *Queries) GetAccountByUserId(ctx context.Context, userID int64) ([]Accou
 			&i.Balance,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DerivationIndex,
 		); err != nil {
 			return nil, err
 		}
@@ -105,3 +136,58 @@ func (q *Queries) GetAccountByUserId(ctx context.Context, userID int64) ([]Accou
 	}
 	return items, nil
 }
+
+const listAccountsByAddresses = `-- name: ListAccountsByAddresses :many
+SELECT id, user_id, address, chain_id, balance, created_at, updated_at, derivation_index FROM accounts WHERE chain_id = $1 AND address = ANY($2::VARCHAR[])
+`
+
+type ListAccountsByAddressesParams struct {
+	ChainID   int32    `json:"chain_id"`
+	Addresses []string `json:"addresses"`
+}
+
+func (q *Queries) ListAccountsByAddresses(ctx context.Context, arg ListAccountsByAddressesParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsByAddresses, arg.ChainID, arg.Addresses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Address,
+			&i.ChainID,
+			&i.Balance,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DerivationIndex,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateAccountBalance = `-- name: UpdateAccountBalance :exec
+UPDATE accounts
+SET balance = $3, updated_at = CURRENT_TIMESTAMP
+WHERE address = $1 AND chain_id = $2
+`
+
+type UpdateAccountBalanceParams struct {
+	Address string         `json:"address"`
+	ChainID int32          `json:"chain_id"`
+	Balance pgtype.Numeric `json:"balance"`
+}
+
+func (q *Queries) UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) error {
+	_, err := q.db.Exec(ctx, updateAccountBalance, arg.Address, arg.ChainID, arg.Balance)
+	return err
+}
@@ -9,15 +9,216 @@ import (
 )
 
 type Account struct {
+	ID              int64            `json:"id"`
+	UserID          int64            `json:"user_id"`
+	Address         string           `json:"address"`
+	ChainID         int32            `json:"chain_id"`
+	Balance         pgtype.Numeric   `json:"balance"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+	DerivationIndex pgtype.Int4      `json:"derivation_index"`
+}
+
+type AccountNonce struct {
+	ChainID   int32            `json:"chain_id"`
+	Address   string           `json:"address"`
+	NextNonce int64            `json:"next_nonce"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type ApprovalRequest struct {
+	TransactionID     int64            `json:"transaction_id"`
+	RuleID            pgtype.Int8      `json:"rule_id"`
+	RequiredApprovals int32            `json:"required_approvals"`
+	ApproverIds       []int64          `json:"approver_ids"`
+	ExpiresAt         pgtype.Timestamp `json:"expires_at"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+}
+
+type ApprovalRule struct {
+	ID                int64            `json:"id"`
+	AccountID         int64            `json:"account_id"`
+	TokenAddress      pgtype.Text      `json:"token_address"`
+	Threshold         pgtype.Numeric   `json:"threshold"`
+	RequiredApprovals int32            `json:"required_approvals"`
+	ApproverIds       []int64          `json:"approver_ids"`
+	TtlSeconds        int32            `json:"ttl_seconds"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
+type ApprovalRuleDeletion struct {
+	RuleID      int64            `json:"rule_id"`
+	RequestedBy int64            `json:"requested_by"`
+	ApprovedBy  []int64          `json:"approved_by"`
+	ExpiresAt   pgtype.Timestamp `json:"expires_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type Contact struct {
 	ID        int64            `json:"id"`
 	UserID    int64            `json:"user_id"`
-	Address   string           `json:"address"`
 	ChainID   int32            `json:"chain_id"`
-	Balance   pgtype.Numeric   `json:"balance"`
+	Label     string           `json:"label"`
+	Address   string           `json:"address"`
+	UsableAt  pgtype.Timestamp `json:"usable_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type Deposit struct {
+	ID           int64            `json:"id"`
+	AccountID    int64            `json:"account_id"`
+	ChainID      int32            `json:"chain_id"`
+	TxHash       string           `json:"tx_hash"`
+	FromAddress  string           `json:"from_address"`
+	ToAddress    string           `json:"to_address"`
+	Value        pgtype.Numeric   `json:"value"`
+	BlockNumber  int64            `json:"block_number"`
+	BlockHash    string           `json:"block_hash"`
+	Status       string           `json:"status"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	TokenAddress pgtype.Text      `json:"token_address"`
+	LogIndex     int32            `json:"log_index"`
+}
+
+type IdempotencyKey struct {
+	ID                  int64            `json:"id"`
+	UserID              int64            `json:"user_id"`
+	IdempotencyKey      string           `json:"idempotency_key"`
+	RequestHash         string           `json:"request_hash"`
+	ResponseStatus      pgtype.Int4      `json:"response_status"`
+	ResponseContentType pgtype.Text      `json:"response_content_type"`
+	ResponseBody        []byte           `json:"response_body"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `json:"updated_at"`
+	CompletedAt         pgtype.Timestamp `json:"completed_at"`
+}
+
+type Keystore struct {
+	ID        int64            `json:"id"`
+	AccountID int64            `json:"account_id"`
+	Crypto    []byte           `json:"crypto"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
 
+type OutboxEvent struct {
+	ID          int64            `json:"id"`
+	QueueName   string           `json:"queue_name"`
+	Payload     []byte           `json:"payload"`
+	Attempts    int32            `json:"attempts"`
+	LastError   pgtype.Text      `json:"last_error"`
+	PublishedAt pgtype.Timestamp `json:"published_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	Producer    string           `json:"producer"`
+}
+
+type ProcessedEvent struct {
+	Consumer    string           `json:"consumer"`
+	EventID     string           `json:"event_id"`
+	ProcessedAt pgtype.Timestamp `json:"processed_at"`
+}
+
+type ReleasedNonce struct {
+	ChainID   int32            `json:"chain_id"`
+	Address   string           `json:"address"`
+	Nonce     int64            `json:"nonce"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type ScanCursor struct {
+	ChainID     int32            `json:"chain_id"`
+	BlockNumber int64            `json:"block_number"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	BlockHash   string           `json:"block_hash"`
+}
+
+type ScannedBlock struct {
+	ChainID     int32            `json:"chain_id"`
+	BlockNumber int64            `json:"block_number"`
+	BlockHash   string           `json:"block_hash"`
+	ParentHash  string           `json:"parent_hash"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type Session struct {
+	ID               int64            `json:"id"`
+	UserID           int64            `json:"user_id"`
+	AccessTokenHash  string           `json:"access_token_hash"`
+	RefreshTokenHash string           `json:"refresh_token_hash"`
+	AccessExpiresAt  pgtype.Timestamp `json:"access_expires_at"`
+	RefreshExpiresAt pgtype.Timestamp `json:"refresh_expires_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+}
+
+type SpendingPolicy struct {
+	ID            int64            `json:"id"`
+	UserID        int64            `json:"user_id"`
+	AccountID     pgtype.Int8      `json:"account_id"`
+	ChainID       pgtype.Int4      `json:"chain_id"`
+	Kind          string           `json:"kind"`
+	TokenAddress  pgtype.Text      `json:"token_address"`
+	MaxAmount     pgtype.Numeric   `json:"max_amount"`
+	WindowSeconds pgtype.Int4      `json:"window_seconds"`
+	Addresses     []string         `json:"addresses"`
+	StartMinute   pgtype.Int4      `json:"start_minute"`
+	EndMinute     pgtype.Int4      `json:"end_minute"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+	DeleteAfter   pgtype.Timestamp `json:"delete_after"`
+}
+
+type TrackedTransaction struct {
+	TransactionID  int64            `json:"transaction_id"`
+	ChainID        int32            `json:"chain_id"`
+	TxHash         string           `json:"tx_hash"`
+	FromAddress    string           `json:"from_address"`
+	ToAddress      string           `json:"to_address"`
+	Nonce          int64            `json:"nonce"`
+	CorrelationID  string           `json:"correlation_id"`
+	MinedBlockHash pgtype.Text      `json:"mined_block_hash"`
+	StartedAt      pgtype.Timestamp `json:"started_at"`
+	FinishedAt     pgtype.Timestamp `json:"finished_at"`
+}
+
+type Transaction struct {
+	ID                   int64            `json:"id"`
+	AccountID            int64            `json:"account_id"`
+	ChainID              int32            `json:"chain_id"`
+	FromAddress          string           `json:"from_address"`
+	ToAddress            string           `json:"to_address"`
+	Value                pgtype.Numeric   `json:"value"`
+	Nonce                pgtype.Int8      `json:"nonce"`
+	GasLimit             pgtype.Int8      `json:"gas_limit"`
+	GasPrice             pgtype.Numeric   `json:"gas_price"`
+	Hash                 pgtype.Text      `json:"hash"`
+	Status               string           `json:"status"`
+	Error                pgtype.Text      `json:"error"`
+	CreatedAt            pgtype.Timestamp `json:"created_at"`
+	UpdatedAt            pgtype.Timestamp `json:"updated_at"`
+	BlockNumber          pgtype.Int8      `json:"block_number"`
+	BlockHash            pgtype.Text      `json:"block_hash"`
+	TxType               int32            `json:"tx_type"`
+	MaxFeePerGas         pgtype.Numeric   `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas pgtype.Numeric   `json:"max_priority_fee_per_gas"`
+	TokenAddress         pgtype.Text      `json:"token_address"`
+	ReplacesID           pgtype.Int8      `json:"replaces_id"`
+}
+
+type TransactionApproval struct {
+	ID            int64            `json:"id"`
+	TransactionID int64            `json:"transaction_id"`
+	UserID        int64            `json:"user_id"`
+	Decision      string           `json:"decision"`
+	Comment       pgtype.Text      `json:"comment"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
 type User struct {
 	ID                 int64            `json:"id"`
 	Email              string           `json:"email"`
@@ -25,3 +226,38 @@ type User struct {
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
 }
+
+type WalletSeed struct {
+	ID        int64            `json:"id"`
+	UserID    int64            `json:"user_id"`
+	Crypto    []byte           `json:"crypto"`
+	NextIndex int32            `json:"next_index"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	SubscriptionID int64            `json:"subscription_id"`
+	EventID        string           `json:"event_id"`
+	EventType      string           `json:"event_type"`
+	Payload        []byte           `json:"payload"`
+	Status         string           `json:"status"`
+	Attempts       int32            `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamp `json:"next_attempt_at"`
+	ResponseStatus pgtype.Int4      `json:"response_status"`
+	LastError      pgtype.Text      `json:"last_error"`
+	DeliveredAt    pgtype.Timestamp `json:"delivered_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type WebhookSubscription struct {
+	ID         int64            `json:"id"`
+	UserID     int64            `json:"user_id"`
+	URL        string           `json:"url"`
+	Secret     string           `json:"secret"`
+	EventTypes []string         `json:"event_types"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+	UpdatedAt  pgtype.Timestamp `json:"updated_at"`
+}
@@ -0,0 +1,175 @@
+// Package event defines the messages exchanged between the wallet and scanner
+// services. Every message is an Envelope whose payload type is fixed by the
+// envelope's Type.
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion is the major version of the envelope and payload schema this
+// build produces and accepts. Only breaking changes bump it; adding fields
+// bumps SchemaMinorVersion, which consumers accept whatever its value since
+// they ignore fields they do not know.
+const (
+	SchemaVersion      = 1
+	SchemaMinorVersion = 1
+)
+
+// Event types.
+const (
+	TypeTransactionSubmitted = "transaction.submitted"
+	TypeTransactionMined     = "transaction.mined"
+	TypeTransactionConfirmed = "transaction.confirmed"
+	// TypeTransactionReorged reports that the block holding a mined
+	// transaction was reorganized away; it is pending again.
+	TypeTransactionReorged = "transaction.reorged"
+	TypeTransactionFailed  = "transaction.failed"
+	TypeDepositDetected    = "deposit.detected"
+	TypeDepositConfirmed   = "deposit.confirmed"
+	TypeDepositReverted    = "deposit.reverted"
+)
+
+// Queues the services exchange events on. The wallet announces submitted
+// transactions on ScanQueue; the scanner reports on ResultQueue and
+// DepositQueue.
+const (
+	ScanQueue    = "scan_queue"
+	ResultQueue  = "scan_result_queue"
+	DepositQueue = "deposit_queue"
+)
+
+var (
+	ErrUnknownType        = errors.New("unknown event type")
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+	ErrInvalidEvent       = errors.New("invalid event")
+)
+
+// Envelope carries one event together with the metadata consumers need to
+// route and deduplicate it.
+type Envelope struct {
+	ID                 string    `json:"id"`
+	Type               string    `json:"type"`
+	SchemaVersion      int       `json:"schema_version"`
+	SchemaMinorVersion int       `json:"schema_minor_version,omitempty"`
+	OccurredAt         time.Time `json:"occurred_at"`
+	ChainID            string    `json:"chain_id"`
+	// CorrelationID ties together every event about the same transaction
+	// or deposit.
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Payload is the type-specific body of an event.
+type Payload interface {
+	Validate() error
+}
+
+// payloads maps each event type to the payload it carries.
+var payloads = map[string]func() Payload{
+	TypeTransactionSubmitted: func() Payload { return &TransactionSubmitted{} },
+	TypeTransactionMined:     func() Payload { return &TransactionConfirmed{} },
+	TypeTransactionConfirmed: func() Payload { return &TransactionConfirmed{} },
+	TypeTransactionReorged:   func() Payload { return &TransactionConfirmed{} },
+	TypeTransactionFailed:    func() Payload { return &TransactionFailed{} },
+	TypeDepositDetected:      func() Payload { return &DepositDetected{} },
+	TypeDepositConfirmed:     func() Payload { return &DepositDetected{} },
+	TypeDepositReverted:      func() Payload { return &DepositDetected{} },
+}
+
+// New wraps payload in a fresh envelope of the given type.
+func New(eventType, chainID, correlationID string, payload Payload) (Envelope, error) {
+	err := validate(eventType, payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		ID:                 uuid.NewString(),
+		Type:               eventType,
+		SchemaVersion:      SchemaVersion,
+		SchemaMinorVersion: SchemaMinorVersion,
+		OccurredAt:         time.Now().UTC(),
+		ChainID:            chainID,
+		CorrelationID:      correlationID,
+		Payload:            body,
+	}, nil
+}
+
+// validate checks that payload is the one eventType carries and that it is
+// complete.
+func validate(eventType string, payload Payload) error {
+	newPayload, ok := payloads[eventType]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownType, eventType)
+	}
+	if reflect.TypeOf(newPayload()) != reflect.TypeOf(payload) {
+		return fmt.Errorf("%w: %s does not carry %T", ErrInvalidEvent, eventType, payload)
+	}
+	return payload.Validate()
+}
+
+// Parse decodes and validates a message body, returning the envelope and its
+// typed payload. Events of another major schema version are rejected; newer
+// minor versions are accepted and the fields they add are ignored, so a
+// producer can be upgraded before its consumers.
+func Parse(body []byte) (Envelope, Payload, error) {
+	envelope := Envelope{}
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		return Envelope{}, nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	switch {
+	case envelope.SchemaVersion != SchemaVersion:
+		return Envelope{}, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, envelope.SchemaVersion)
+	case envelope.ID == "":
+		return Envelope{}, nil, fmt.Errorf("%w: missing id", ErrInvalidEvent)
+	case envelope.OccurredAt.IsZero():
+		return Envelope{}, nil, fmt.Errorf("%w: missing occurred_at", ErrInvalidEvent)
+	case envelope.ChainID == "":
+		return Envelope{}, nil, fmt.Errorf("%w: missing chain_id", ErrInvalidEvent)
+	}
+	if _, err := strconv.ParseInt(envelope.ChainID, 10, 64); err != nil {
+		return Envelope{}, nil, fmt.Errorf("%w: malformed chain_id %q", ErrInvalidEvent, envelope.ChainID)
+	}
+
+	newPayload, ok := payloads[envelope.Type]
+	if !ok {
+		return Envelope{}, nil, fmt.Errorf("%w: %q", ErrUnknownType, envelope.Type)
+	}
+	payload := newPayload()
+	err = json.Unmarshal(envelope.Payload, payload)
+	if err != nil {
+		return Envelope{}, nil, fmt.Errorf("%w: %s payload: %v", ErrInvalidEvent, envelope.Type, err)
+	}
+	err = payload.Validate()
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+	return envelope, payload, nil
+}
+
+// TransactionCorrelationID is the correlation ID of every event about the
+// wallet transaction id.
+func TransactionCorrelationID(id int64) string {
+	return "transaction-" + strconv.FormatInt(id, 10)
+}
+
+// DepositCorrelationID is the correlation ID of every event about the deposit
+// id.
+func DepositCorrelationID(id int64) string {
+	return "deposit-" + strconv.FormatInt(id, 10)
+}
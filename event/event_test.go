@@ -0,0 +1,128 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func submitted() *TransactionSubmitted {
+	return &TransactionSubmitted{
+		TransactionID:   7,
+		TransactionHash: "0xabc",
+		FromAddress:     "0x1",
+		ToAddress:       "0x2",
+		Value:           "1000",
+		Nonce:           3,
+	}
+}
+
+// encode marshals envelope and applies edit to its generic JSON form.
+func encode(t *testing.T, envelope Envelope, edit func(map[string]any)) []byte {
+	t.Helper()
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]any{}
+	err = json.Unmarshal(body, &fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(fields)
+	body, err = json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	envelope, err := New(TypeTransactionSubmitted, "11155111", TransactionCorrelationID(7), submitted())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, payload, err := Parse(body)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if parsed.ID != envelope.ID || parsed.CorrelationID != "transaction-7" {
+		t.Errorf("parsed envelope = %+v, want %+v", parsed, envelope)
+	}
+	got, ok := payload.(*TransactionSubmitted)
+	if !ok {
+		t.Fatalf("payload is %T, want *TransactionSubmitted", payload)
+	}
+	if *got != *submitted() {
+		t.Errorf("payload = %+v, want %+v", *got, *submitted())
+	}
+}
+
+func TestParseAcceptsNewerMinorVersion(t *testing.T) {
+	envelope, err := New(TypeTransactionSubmitted, "1", "", submitted())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	body := encode(t, envelope, func(fields map[string]any) {
+		fields["schema_minor_version"] = SchemaMinorVersion + 1
+		fields["trace_id"] = "abc"
+		fields["payload"].(map[string]any)["gas_used"] = 21000
+	})
+
+	_, payload, err := Parse(body)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := payload.(*TransactionSubmitted); *got != *submitted() {
+		t.Errorf("payload = %+v, want %+v", *got, *submitted())
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	envelope, err := New(TypeTransactionSubmitted, "1", "", submitted())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(map[string]any)
+		want error
+	}{
+		{"unknown major version", func(f map[string]any) { f["schema_version"] = SchemaVersion + 1 }, ErrUnsupportedVersion},
+		{"missing version", func(f map[string]any) { delete(f, "schema_version") }, ErrUnsupportedVersion},
+		{"unknown type", func(f map[string]any) { f["type"] = "transaction.teleported" }, ErrUnknownType},
+		{"missing id", func(f map[string]any) { delete(f, "id") }, ErrInvalidEvent},
+		{"missing occurred_at", func(f map[string]any) { delete(f, "occurred_at") }, ErrInvalidEvent},
+		{"malformed chain_id", func(f map[string]any) { f["chain_id"] = "mainnet" }, ErrInvalidEvent},
+		{"incomplete payload", func(f map[string]any) { delete(f["payload"].(map[string]any), "transaction_hash") }, ErrInvalidEvent},
+		{"mistyped payload field", func(f map[string]any) { f["payload"].(map[string]any)["nonce"] = "three" }, ErrInvalidEvent},
+	}
+	for _, tt := range tests {
+		_, _, err := Parse(encode(t, envelope, tt.edit))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	_, _, err = Parse([]byte("not json"))
+	if !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("malformed body: error = %v, want ErrInvalidEvent", err)
+	}
+}
+
+func TestNewRejectsMismatchedPayload(t *testing.T) {
+	_, err := New(TypeDepositDetected, "1", "", submitted())
+	if !errors.Is(err, ErrInvalidEvent) {
+		t.Errorf("error = %v, want ErrInvalidEvent", err)
+	}
+	_, err = New("transaction.teleported", "1", "", submitted())
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("error = %v, want ErrUnknownType", err)
+	}
+}
@@ -0,0 +1,163 @@
+package event
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Reasons a transaction failed, carried by TransactionFailed.
+const (
+	// FailureExecutionReverted means the transaction was mined but its
+	// execution reverted.
+	FailureExecutionReverted = "execution_reverted"
+	// FailureDropped means the network no longer knows the transaction.
+	FailureDropped = "dropped"
+	// FailureReplaced means another transaction with the same nonce was
+	// mined instead.
+	FailureReplaced = "replaced"
+)
+
+// TransactionSubmitted announces a signed transaction that was broadcast and
+// should be tracked until it is final. For token transfers ToAddress is the
+// token contract and Value is zero.
+type TransactionSubmitted struct {
+	TransactionID   int64  `json:"transaction_id"`
+	TransactionHash string `json:"transaction_hash"`
+	FromAddress     string `json:"from_address"`
+	ToAddress       string `json:"to_address"`
+	Value           string `json:"value"`
+	Nonce           uint64 `json:"nonce"`
+}
+
+func (p *TransactionSubmitted) Validate() error {
+	err := requireTransaction(p.TransactionID, p.TransactionHash, p.FromAddress)
+	if err != nil {
+		return err
+	}
+	if p.ToAddress == "" {
+		return invalid("missing to_address")
+	}
+	return requireAmount("value", p.Value)
+}
+
+// TransactionConfirmed reports a transaction's progress towards finality: it
+// was mined, reached the required confirmations, or (for
+// TypeTransactionReorged) lost its block again. Balance is the sender's
+// balance at the time, if known.
+type TransactionConfirmed struct {
+	TransactionID   int64  `json:"transaction_id"`
+	TransactionHash string `json:"transaction_hash"`
+	FromAddress     string `json:"from_address"`
+	BlockNumber     uint64 `json:"block_number,omitempty"`
+	BlockHash       string `json:"block_hash,omitempty"`
+	Confirmations   uint64 `json:"confirmations,omitempty"`
+	Balance         string `json:"balance,omitempty"`
+}
+
+func (p *TransactionConfirmed) Validate() error {
+	err := requireTransaction(p.TransactionID, p.TransactionHash, p.FromAddress)
+	if err != nil {
+		return err
+	}
+	if p.Balance != "" {
+		return requireAmount("balance", p.Balance)
+	}
+	return nil
+}
+
+// TransactionFailed reports that a transaction will never succeed.
+// ReplacedBy holds the winning hash when Reason is FailureReplaced.
+type TransactionFailed struct {
+	TransactionID   int64  `json:"transaction_id"`
+	TransactionHash string `json:"transaction_hash"`
+	FromAddress     string `json:"from_address"`
+	Reason          string `json:"reason"`
+	BlockNumber     uint64 `json:"block_number,omitempty"`
+	BlockHash       string `json:"block_hash,omitempty"`
+	ReplacedBy      string `json:"replaced_by,omitempty"`
+	Balance         string `json:"balance,omitempty"`
+}
+
+func (p *TransactionFailed) Validate() error {
+	err := requireTransaction(p.TransactionID, p.TransactionHash, p.FromAddress)
+	if err != nil {
+		return err
+	}
+	switch p.Reason {
+	case FailureExecutionReverted, FailureDropped:
+	case FailureReplaced:
+		if p.ReplacedBy == "" {
+			return invalid("missing replaced_by")
+		}
+	default:
+		return invalid(fmt.Sprintf("unknown reason %q", p.Reason))
+	}
+	if p.Balance != "" {
+		return requireAmount("balance", p.Balance)
+	}
+	return nil
+}
+
+// DepositDetected describes an inbound transfer to one of our accounts. It is
+// sent when the transfer is first seen and again, with the recipient's
+// balance, when it is confirmed or reverted by a reorg. TokenAddress is set
+// for ERC-20 deposits, whose Value is in the token's base units and which
+// carry no balance.
+type DepositDetected struct {
+	DepositID    int64  `json:"deposit_id"`
+	AccountID    int64  `json:"account_id"`
+	TxHash       string `json:"tx_hash"`
+	FromAddress  string `json:"from_address"`
+	ToAddress    string `json:"to_address"`
+	Value        string `json:"value"`
+	BlockNumber  uint64 `json:"block_number"`
+	Balance      string `json:"balance,omitempty"`
+	TokenAddress string `json:"token_address,omitempty"`
+}
+
+func (p *DepositDetected) Validate() error {
+	switch {
+	case p.DepositID <= 0:
+		return invalid("missing deposit_id")
+	case p.AccountID <= 0:
+		return invalid("missing account_id")
+	case p.TxHash == "":
+		return invalid("missing tx_hash")
+	case p.ToAddress == "":
+		return invalid("missing to_address")
+	}
+	err := requireAmount("value", p.Value)
+	if err != nil {
+		return err
+	}
+	if p.Balance != "" {
+		return requireAmount("balance", p.Balance)
+	}
+	return nil
+}
+
+func requireTransaction(id int64, hash, from string) error {
+	switch {
+	case id <= 0:
+		return invalid("missing transaction_id")
+	case hash == "":
+		return invalid("missing transaction_hash")
+	case from == "":
+		return invalid("missing from_address")
+	}
+	return nil
+}
+
+// requireAmount checks that value is a non-negative base-10 integer, the
+// encoding used for every wei amount.
+func requireAmount(field, value string) error {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() < 0 {
+		return invalid(fmt.Sprintf("malformed %s %q", field, value))
+	}
+	return nil
+}
+
+func invalid(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidEvent, reason)
+}
@@ -0,0 +1,44 @@
+package queue
+
+import (
+	"context"
+	"strings"
+)
+
+// MemoryURI selects the in-process bus instead of a RabbitMQ broker. Each
+// service gets its own, so services on MemoryURI do not reach one another.
+const MemoryURI = "memory://"
+
+// Bus carries messages between the services. Publish delivers to a named
+// queue and Subscribe competes with other subscribers of that queue for its
+// messages, so the services never depend on how messages are transported.
+type Bus interface {
+	// Publish returns once the message is durably queued.
+	Publish(ctx context.Context, name string, body []byte) error
+	// Subscribe hands messages on name to handle until the bus is closed.
+	// Messages that keep failing are moved to the dead-letter queue of name.
+	Subscribe(name string, prefetch int, policy RetryPolicy, handle Handler)
+	// PeekDeadLetters returns up to limit dead-lettered messages of name
+	// without removing them.
+	PeekDeadLetters(name string, limit int) ([]DeadLetter, error)
+	// ReplayDeadLetters moves up to limit dead-lettered messages of name back
+	// onto name and returns how many were moved.
+	ReplayDeadLetters(ctx context.Context, name string, limit int) (int, error)
+	// State reports whether the bus can currently carry messages.
+	State() string
+	Close()
+}
+
+var (
+	_ Bus = (*Connection)(nil)
+	_ Bus = (*Memory)(nil)
+)
+
+// Open returns the in-process bus for MemoryURI and a RabbitMQ connection
+// for any other uri. queues are declared up front on RabbitMQ.
+func Open(uri string, queues ...string) Bus {
+	if strings.HasPrefix(uri, MemoryURI) {
+		return NewMemory()
+	}
+	return Dial(uri, queues...)
+}
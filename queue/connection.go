@@ -0,0 +1,278 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Connection states reported by State.
+const (
+	StateConnecting   = "connecting"
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting"
+	StateClosed       = "closed"
+)
+
+const (
+	initialReconnectBackoff = time.Second
+	maxReconnectBackoff     = 30 * time.Second
+)
+
+var (
+	ErrNotConnected = errors.New("queue connection is not established")
+	ErrClosed       = errors.New("queue connection is closed")
+
+	errPublishNacked = errors.New("broker did not confirm the message")
+)
+
+// Connection keeps a RabbitMQ connection alive. It dials in the background,
+// redials with exponential backoff whenever the broker closes the connection,
+// and re-declares the registered queues after every reconnect.
+type Connection struct {
+	uri    string
+	queues []string
+
+	mu        sync.RWMutex
+	conn      *amqp.Connection
+	state     string
+	connected chan struct{}
+
+	// publishMu guards publishCh, the confirm channel shared by publishers.
+	publishMu sync.Mutex
+	publishCh *amqp.Channel
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Dial starts maintaining a connection to uri and returns immediately; use
+// State or WaitConnected to find out when it is up. queues are declared on
+// every (re)connect.
+func Dial(uri string, queues ...string) *Connection {
+	c := &Connection{
+		uri:       uri,
+		queues:    queues,
+		state:     StateConnecting,
+		connected: make(chan struct{}),
+		closed:    make(chan struct{}),
+	}
+	go c.maintain()
+	return c
+}
+
+func (c *Connection) maintain() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	backoff := initialReconnectBackoff
+	for {
+		conn, err := c.connect()
+		if err != nil {
+			logger.Error("Failed to connect to RabbitMQ",
+				slog.Duration("retry_in", backoff),
+				slog.Any("error", err),
+			)
+			select {
+			case <-c.closed:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxReconnectBackoff)
+			continue
+		}
+		backoff = initialReconnectBackoff
+
+		notify := conn.NotifyClose(make(chan *amqp.Error, 1))
+		c.setConnected(conn)
+		logger.Info("Connected to RabbitMQ")
+
+		select {
+		case <-c.closed:
+			conn.Close()
+			return
+		case amqpErr := <-notify:
+			c.setDisconnected()
+			logger.Warn("RabbitMQ connection lost, reconnecting",
+				slog.Any("error", amqpErr),
+			)
+		}
+	}
+}
+
+// connect dials the broker and declares the registered topology.
+func (c *Connection) connect() (*amqp.Connection, error) {
+	conn, err := amqp.Dial(c.uri)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	defer ch.Close()
+
+	for _, name := range c.queues {
+		_, err := Declare(ch, name)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c *Connection) setConnected(conn *amqp.Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+	c.state = StateConnected
+	close(c.connected)
+}
+
+func (c *Connection) setDisconnected() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = nil
+	c.state = StateReconnecting
+	c.connected = make(chan struct{})
+}
+
+// State reports the current connection state.
+func (c *Connection) State() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// Channel opens a channel on the current connection.
+func (c *Connection) Channel() (*amqp.Channel, error) {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	return conn.Channel()
+}
+
+// WaitConnected blocks until the connection is up, ctx is done, or the
+// connection is closed.
+func (c *Connection) WaitConnected(ctx context.Context) error {
+	c.mu.RLock()
+	connected := c.connected
+	c.mu.RUnlock()
+
+	select {
+	case <-connected:
+		return nil
+	case <-c.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Publish publishes body as a persistent message onto name and waits for the
+// broker to confirm it.
+func (c *Connection) Publish(ctx context.Context, name string, body []byte) error {
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+
+	if c.publishCh == nil || c.publishCh.IsClosed() {
+		ch, err := c.Channel()
+		if err != nil {
+			return err
+		}
+		err = ch.Confirm(false)
+		if err != nil {
+			ch.Close()
+			return err
+		}
+		c.publishCh = ch
+	}
+
+	_, err := Declare(c.publishCh, name)
+	if err != nil {
+		return err
+	}
+	return publishConfirmed(ctx, c.publishCh, name, body)
+}
+
+// publishConfirmed publishes body onto name over a channel in confirm mode and
+// waits for the broker's acknowledgement.
+func publishConfirmed(ctx context.Context, ch *amqp.Channel, name string, body []byte) error {
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(
+		ctx,
+		"",    // exchange
+		name,  // routing key
+		false, // mandatory
+		false, // immediate
+		Persistent(body),
+	)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return errPublishNacked
+	}
+	return nil
+}
+
+// Subscribe runs a consumer for name that survives reconnects: whenever its
+// channel goes away it waits for the connection and starts again. It returns
+// once the connection is closed.
+func (c *Connection) Subscribe(name string, prefetch int, policy RetryPolicy, handle Handler) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("queue", name),
+	)
+
+	for {
+		if err := c.WaitConnected(context.Background()); err != nil {
+			return
+		}
+
+		ch, err := c.Channel()
+		if err == nil {
+			err = Consume(ch, name, prefetch, policy, handle)
+			ch.Close()
+		}
+
+		select {
+		case <-c.closed:
+			return
+		default:
+		}
+
+		logger.Warn("Consumer stopped, restarting", slog.Any("error", err))
+		time.Sleep(initialReconnectBackoff)
+	}
+}
+
+// Close shuts the connection down for good.
+func (c *Connection) Close() {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.state = StateClosed
+		conn := c.conn
+		c.conn = nil
+		c.mu.Unlock()
+
+		close(c.closed)
+		if conn != nil {
+			conn.Close()
+		}
+	})
+}
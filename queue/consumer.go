@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Handler processes one message body.
+type Handler func(ctx context.Context, body []byte) error
+
+// RetryPolicy bounds how often a failing message is retried before it is
+// dead-lettered. Backoff doubles after each attempt up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// HandleTimeout limits a single attempt.
+	HandleTimeout time.Duration
+}
+
+// DefaultPrefetch bounds how many unacknowledged messages a consumer holds.
+const DefaultPrefetch = 10
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	HandleTimeout:  10 * time.Second,
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the message is dead-lettered without retries, e.g.
+// when it cannot be decoded.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// Consume delivers messages on name to handle until the channel closes.
+// Messages are acknowledged only after handle succeeds; failures are retried
+// with exponential backoff and then rejected into the dead-letter queue. Up
+// to prefetch messages are handled concurrently.
+func Consume(ch *amqp.Channel, name string, prefetch int, policy RetryPolicy, handle Handler) error {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("queue", name),
+	)
+
+	q, err := Declare(ch, name)
+	if err != nil {
+		return err
+	}
+
+	err = ch.Qos(prefetch, 0, false)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(
+		q.Name, // queue
+		"",     // consumer
+		false,  // auto-ack
+		false,  // exclusive
+		false,  // no-local
+		false,  // no-wait
+		nil,    // args
+	)
+	if err != nil {
+		return err
+	}
+
+	logger.Info(" [*] Waiting for messages")
+	for d := range msgs {
+		go deliver(logger, d.Body, policy, handle,
+			func() error { return d.Ack(false) },
+			func() error { return d.Nack(false, false) },
+		)
+	}
+	return nil
+}
+
+// deliver runs handle on body under policy, then calls ack on success or
+// reject once the message should be dead-lettered.
+func deliver(logger *slog.Logger, body []byte, policy RetryPolicy, handle Handler, ack, reject func() error) {
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), policy.HandleTimeout)
+		err := handle(ctx, body)
+		cancel()
+		if err == nil {
+			if ackErr := ack(); ackErr != nil {
+				logger.Error("Failed to ack message", slog.Any("error", ackErr))
+			}
+			return
+		}
+
+		if isPermanent(err) || attempt >= policy.MaxAttempts {
+			logger.Error("Dead-lettering message",
+				slog.String("message", string(body)),
+				slog.Int("attempts", attempt),
+				slog.Any("error", err),
+			)
+			if nackErr := reject(); nackErr != nil {
+				logger.Error("Failed to reject message", slog.Any("error", nackErr))
+			}
+			return
+		}
+
+		logger.Warn("Failed to handle message, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+			slog.Any("error", err),
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, policy.MaxBackoff)
+	}
+}
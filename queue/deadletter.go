@@ -0,0 +1,100 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter is a message sitting in a dead-letter queue.
+type DeadLetter struct {
+	Body       string    `json:"body"`
+	Timestamp  time.Time `json:"timestamp"`
+	Reason     string    `json:"reason,omitempty"`
+	DeathCount int64     `json:"death_count,omitempty"`
+}
+
+func newDeadLetter(d amqp.Delivery) DeadLetter {
+	letter := DeadLetter{Body: string(d.Body), Timestamp: d.Timestamp}
+	if deaths, ok := d.Headers["x-death"].([]interface{}); ok && len(deaths) > 0 {
+		if death, ok := deaths[0].(amqp.Table); ok {
+			letter.Reason, _ = death["reason"].(string)
+			letter.DeathCount, _ = death["count"].(int64)
+		}
+	}
+	return letter
+}
+
+// PeekDeadLetters returns up to limit messages from the dead-letter queue of
+// name without removing them.
+func (c *Connection) PeekDeadLetters(name string, limit int) ([]DeadLetter, error) {
+	ch, err := c.Channel()
+	if err != nil {
+		return nil, err
+	}
+	// Closing the channel returns every unacknowledged message to the queue.
+	defer ch.Close()
+
+	_, err = Declare(ch, name)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []DeadLetter{}
+	for len(letters) < limit {
+		d, ok, err := ch.Get(DeadLetterName(name), false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		letters = append(letters, newDeadLetter(d))
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves up to limit messages from the dead-letter queue of
+// name back onto name and returns how many were moved. Each message is only
+// removed from the dead-letter queue once the broker confirmed the replay.
+func (c *Connection) ReplayDeadLetters(ctx context.Context, name string, limit int) (int, error) {
+	ch, err := c.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	err = ch.Confirm(false)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = Declare(ch, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var replayed int
+	for replayed < limit {
+		d, ok, err := ch.Get(DeadLetterName(name), false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		err = publishConfirmed(ctx, ch, name, d.Body)
+		if err != nil {
+			return replayed, err
+		}
+
+		err = d.Ack(false)
+		if err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
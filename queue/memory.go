@@ -0,0 +1,188 @@
+package queue
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// memoryQueueCapacity bounds how many messages an in-process queue buffers
+// before Publish blocks.
+const memoryQueueCapacity = 1024
+
+// Memory is a Bus that passes messages over Go channels within one process.
+// It applies the same retry and dead-letter rules as RabbitMQ but keeps
+// nothing across restarts and reaches no other process: a service opened on
+// it only hears its own messages. It is meant for tests and for running a
+// single service without a broker.
+type Memory struct {
+	mu     sync.Mutex
+	queues map[string]*memoryQueue
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type memoryQueue struct {
+	messages chan memoryMessage
+	// dead is guarded by Memory.mu.
+	dead []memoryMessage
+}
+
+type memoryMessage struct {
+	body      []byte
+	timestamp time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		queues: make(map[string]*memoryQueue),
+		closed: make(chan struct{}),
+	}
+}
+
+// queue returns the queue called name, creating it on first use.
+func (m *Memory) queue(name string) *memoryQueue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.queues[name]
+	if !ok {
+		q = &memoryQueue{messages: make(chan memoryMessage, memoryQueueCapacity)}
+		m.queues[name] = q
+	}
+	return q
+}
+
+// Publish queues body on name, blocking while the queue is full.
+func (m *Memory) Publish(ctx context.Context, name string, body []byte) error {
+	msg := memoryMessage{
+		body:      append([]byte(nil), body...),
+		timestamp: time.Now(),
+	}
+	return m.enqueue(ctx, m.queue(name), msg)
+}
+
+func (m *Memory) enqueue(ctx context.Context, q *memoryQueue, msg memoryMessage) error {
+	select {
+	case <-m.closed:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case q.messages <- msg:
+		return nil
+	case <-m.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe hands messages on name to handle, at most prefetch at a time,
+// until the bus is closed.
+func (m *Memory) Subscribe(name string, prefetch int, policy RetryPolicy, handle Handler) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil)).With(
+		slog.String("queue", name),
+	)
+
+	q := m.queue(name)
+	slots := make(chan struct{}, max(prefetch, 1))
+
+	logger.Info(" [*] Waiting for messages")
+	for {
+		select {
+		case <-m.closed:
+			return
+		case slots <- struct{}{}:
+		}
+
+		select {
+		case <-m.closed:
+			return
+		case msg := <-q.messages:
+			go deliver(logger, msg.body, policy, handle,
+				func() error {
+					<-slots
+					return nil
+				},
+				func() error {
+					m.mu.Lock()
+					q.dead = append(q.dead, msg)
+					m.mu.Unlock()
+					<-slots
+					return nil
+				},
+			)
+		}
+	}
+}
+
+// PeekDeadLetters returns up to limit dead-lettered messages of name without
+// removing them. A negative limit returns none.
+func (m *Memory) PeekDeadLetters(name string, limit int) ([]DeadLetter, error) {
+	q := m.queue(name)
+	limit = max(limit, 0)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	letters := []DeadLetter{}
+	for _, msg := range q.dead[:min(limit, len(q.dead))] {
+		letters = append(letters, DeadLetter{
+			Body:       string(msg.body),
+			Timestamp:  msg.timestamp,
+			Reason:     "rejected",
+			DeathCount: 1,
+		})
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves up to limit dead-lettered messages of name back onto
+// name and returns how many were moved.
+func (m *Memory) ReplayDeadLetters(ctx context.Context, name string, limit int) (int, error) {
+	q := m.queue(name)
+
+	var replayed int
+	for replayed < limit {
+		m.mu.Lock()
+		if len(q.dead) == 0 {
+			m.mu.Unlock()
+			break
+		}
+		msg := q.dead[0]
+		q.dead = q.dead[1:]
+		m.mu.Unlock()
+
+		err := m.enqueue(ctx, q, msg)
+		if err != nil {
+			m.mu.Lock()
+			q.dead = append([]memoryMessage{msg}, q.dead...)
+			m.mu.Unlock()
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// State reports StateConnected until the bus is closed.
+func (m *Memory) State() string {
+	select {
+	case <-m.closed:
+		return StateClosed
+	default:
+		return StateConnected
+	}
+}
+
+// Close stops every subscriber. Messages still queued are discarded.
+func (m *Memory) Close() {
+	m.closeOnce.Do(func() {
+		close(m.closed)
+	})
+}
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var testPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+	HandleTimeout:  time.Second,
+}
+
+func TestMemoryDelivers(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+
+	got := make(chan string, 1)
+	go bus.Subscribe("events", 1, testPolicy, func(ctx context.Context, body []byte) error {
+		got <- string(body)
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), "events", []byte("hello"))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case body := <-got:
+		if body != "hello" {
+			t.Errorf("received %q, want %q", body, "hello")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was not delivered")
+	}
+}
+
+func TestMemoryDeadLettersAndReplays(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+
+	attempts := make(chan struct{}, 10)
+	fail := make(chan bool, 1)
+	fail <- true
+	delivered := make(chan string, 1)
+	go bus.Subscribe("events", 1, testPolicy, func(ctx context.Context, body []byte) error {
+		attempts <- struct{}{}
+		select {
+		case <-fail:
+			return Permanent(errors.New("malformed"))
+		default:
+			delivered <- string(body)
+			return nil
+		}
+	})
+
+	err := bus.Publish(context.Background(), "events", []byte("poison"))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var letters []DeadLetter
+	deadline := time.Now().Add(time.Second)
+	for len(letters) == 0 && time.Now().Before(deadline) {
+		letters, err = bus.PeekDeadLetters("events", 10)
+		if err != nil {
+			t.Fatalf("PeekDeadLetters: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(letters) != 1 || letters[0].Body != "poison" {
+		t.Fatalf("dead letters = %+v, want the poison message", letters)
+	}
+	if len(attempts) != 1 {
+		t.Errorf("permanent failure was attempted %d times, want 1", len(attempts))
+	}
+
+	replayed, err := bus.ReplayDeadLetters(context.Background(), "events", 10)
+	if err != nil {
+		t.Fatalf("ReplayDeadLetters: %v", err)
+	}
+	if replayed != 1 {
+		t.Errorf("replayed %d messages, want 1", replayed)
+	}
+
+	select {
+	case body := <-delivered:
+		if body != "poison" {
+			t.Errorf("replayed %q, want %q", body, "poison")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("replayed message was not delivered")
+	}
+}
+
+func TestMemoryPeekDeadLettersLimit(t *testing.T) {
+	bus := NewMemory()
+	defer bus.Close()
+
+	q := bus.queue("events")
+	for _, body := range []string{"a", "b", "c"} {
+		q.dead = append(q.dead, memoryMessage{body: []byte(body), timestamp: time.Now()})
+	}
+
+	tests := []struct {
+		limit int
+		want  int
+	}{
+		{-1, 0},
+		{0, 0},
+		{2, 2},
+		{10, 3},
+	}
+	for _, tt := range tests {
+		letters, err := bus.PeekDeadLetters("events", tt.limit)
+		if err != nil {
+			t.Fatalf("PeekDeadLetters(%d): %v", tt.limit, err)
+		}
+		if len(letters) != tt.want {
+			t.Errorf("PeekDeadLetters(%d) returned %d letters, want %d", tt.limit, len(letters), tt.want)
+		}
+	}
+}
+
+func TestMemoryClosed(t *testing.T) {
+	bus := NewMemory()
+	bus.Close()
+
+	if state := bus.State(); state != StateClosed {
+		t.Errorf("State() = %q, want %q", state, StateClosed)
+	}
+	err := bus.Publish(context.Background(), "events", []byte("late"))
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close: error = %v, want ErrClosed", err)
+	}
+}
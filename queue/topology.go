@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// deadLetterSuffix names the queue that collects messages rejected from a
+// work queue.
+const deadLetterSuffix = ".dlq"
+
+// DeadLetterName returns the dead-letter queue for the queue name.
+func DeadLetterName(name string) string {
+	return name + deadLetterSuffix
+}
+
+// Declare declares name as a durable queue whose rejected messages are routed
+// to its dead-letter queue, declaring that queue as well. Every publisher and
+// consumer must declare queues through here so the arguments always match.
+func Declare(ch *amqp.Channel, name string) (amqp.Queue, error) {
+	_, err := ch.QueueDeclare(
+		DeadLetterName(name), // name
+		true,                 // durable
+		false,                // delete when unused
+		false,                // exclusive
+		false,                // no-wait
+		nil,                  // arguments
+	)
+	if err != nil {
+		return amqp.Queue{}, err
+	}
+
+	return ch.QueueDeclare(
+		name,  // name
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": DeadLetterName(name),
+		},
+	)
+}
+
+// Persistent wraps a JSON body in a message that survives a broker restart.
+func Persistent(body []byte) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         body,
+	}
+}
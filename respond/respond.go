@@ -0,0 +1,169 @@
+// Package respond writes the JSON responses of the wallet and scanner HTTP
+// APIs. Every error is sent as an ErrorResponse whose code a client can
+// branch on; internal failures are logged and reported without their details
+// so database and RPC errors never reach the caller.
+package respond
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Error codes shared by both APIs. Services define more specific codes for
+// their own resources.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeInternal         = "internal_error"
+	CodeUnavailable      = "service_unavailable"
+
+	// Codes for transactions the chain refused.
+	CodeInsufficientFunds      = "insufficient_funds"
+	CodeNonceTooLow            = "nonce_too_low"
+	CodeAlreadyKnown           = "already_known"
+	CodeReplacementUnderpriced = "replacement_underpriced"
+	CodeFeeTooLow              = "fee_too_low"
+	CodeGasTooLow              = "gas_too_low"
+	CodeExecutionReverted      = "execution_reverted"
+)
+
+// uniqueViolation is the Postgres error code for a unique index conflict.
+const uniqueViolation = "23505"
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+// chainErrors maps fragments of the errors Ethereum nodes return for rejected
+// transactions to an HTTP status, code and message. Nodes only report these
+// as JSON-RPC messages, so they are matched on text.
+var chainErrors = []struct {
+	fragment string
+	status   int
+	code     string
+	message  string
+}{
+	{"insufficient funds", http.StatusUnprocessableEntity, CodeInsufficientFunds, "Insufficient funds for value and gas"},
+	{"nonce too low", http.StatusConflict, CodeNonceTooLow, "Nonce has already been used"},
+	{"already known", http.StatusConflict, CodeAlreadyKnown, "Transaction is already known"},
+	{"replacement transaction underpriced", http.StatusConflict, CodeReplacementUnderpriced, "Replacement fee is too low"},
+	{"transaction underpriced", http.StatusUnprocessableEntity, CodeFeeTooLow, "Fee is too low"},
+	{"fee cap less than block base fee", http.StatusUnprocessableEntity, CodeFeeTooLow, "Fee is too low"},
+	{"max fee per gas less than block base fee", http.StatusUnprocessableEntity, CodeFeeTooLow, "Fee is too low"},
+	{"intrinsic gas too low", http.StatusUnprocessableEntity, CodeGasTooLow, "Gas limit is too low"},
+	{"gas required exceeds allowance", http.StatusUnprocessableEntity, CodeGasTooLow, "Gas limit is too low"},
+	{"execution reverted", http.StatusUnprocessableEntity, CodeExecutionReverted, "Execution reverted"},
+}
+
+// JSON writes v with the given status.
+func JSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError sends an error response. message is shown to the client as is.
+func WriteError(w http.ResponseWriter, status int, code, message string) {
+	JSON(w, status, ErrorResponse{Error: Error{Code: code, Message: message}})
+}
+
+// Invalid reports the invalid fields of a request.
+func Invalid(w http.ResponseWriter, fields []FieldError) {
+	JSON(w, http.StatusBadRequest, ErrorResponse{Error: Error{
+		Code:    CodeInvalidRequest,
+		Message: "Invalid request",
+		Fields:  fields,
+	}})
+}
+
+// InvalidBody reports a request body that could not be decoded. The decoder's
+// error quotes the body, so it is only logged.
+func InvalidBody(w http.ResponseWriter, err error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger.Info("Malformed request body",
+		slog.Any("error", err),
+	)
+	WriteError(w, http.StatusBadRequest, CodeInvalidRequest, "Malformed request body")
+}
+
+func MethodNotAllowed(w http.ResponseWriter) {
+	WriteError(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed!")
+}
+
+// Internal logs err and reports a failure without its details.
+func Internal(w http.ResponseWriter, err error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger.Error("Request failed",
+		slog.Any("error", err),
+	)
+	WriteError(w, http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// FromError reports err, mapping the database and chain errors callers are
+// expected to see onto their codes. Anything else is an internal error. The
+// client only gets a fixed message per code; err itself is logged.
+func FromError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		WriteError(w, http.StatusNotFound, CodeNotFound, "Not found")
+		return
+	case IsUniqueViolation(err):
+		WriteError(w, http.StatusConflict, CodeConflict, "Already exists")
+		return
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, known := range chainErrors {
+		if strings.Contains(message, known.fragment) {
+			logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+			logger.Info("Transaction rejected by node",
+				slog.String("code", known.code),
+				slog.Any("error", err),
+			)
+			WriteError(w, known.status, known.code, known.message)
+			return
+		}
+	}
+	Internal(w, err)
+}
+
+// ChainErrorMessage returns the fixed message for a transaction rejection
+// reported by a node, and false if err is not one.
+func ChainErrorMessage(err error) (string, bool) {
+	message := strings.ToLower(err.Error())
+	for _, known := range chainErrors {
+		if strings.Contains(message, known.fragment) {
+			return known.message, true
+		}
+	}
+	return "", false
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique index conflict.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BigToNumeric converts v to a NUMERIC, mapping nil to NULL.
+func BigToNumeric(v *big.Int) pgtype.Numeric {
+	if v == nil {
+		return pgtype.Numeric{}
+	}
+	return pgtype.Numeric{Int: new(big.Int).Set(v), Valid: true}
+}
+
+// NumericToBig converts n to an integer, mapping NULL to nil and truncating
+// any fractional part.
+func NumericToBig(n pgtype.Numeric) *big.Int {
+	if !n.Valid || n.Int == nil {
+		return nil
+	}
+	v := new(big.Int).Set(n.Int)
+	if n.Exp > 0 {
+		v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp)), nil))
+	} else if n.Exp < 0 {
+		v.Quo(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n.Exp)), nil))
+	}
+	return v
+}
+
+// NumericString formats n as a decimal integer, or "" for NULL.
+func NumericString(n pgtype.Numeric) string {
+	v := NumericToBig(n)
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
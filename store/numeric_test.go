@@ -0,0 +1,29 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestNumericString(t *testing.T) {
+	tests := []struct {
+		n    pgtype.Numeric
+		want string
+	}{
+		{pgtype.Numeric{}, ""},
+		{pgtype.Numeric{Int: big.NewInt(0), Valid: true}, "0"},
+		{pgtype.Numeric{Int: big.NewInt(1500), Valid: true}, "1500"},
+		{pgtype.Numeric{Int: big.NewInt(15), Exp: 2, Valid: true}, "1500"},
+		{pgtype.Numeric{Int: big.NewInt(1599), Exp: -2, Valid: true}, "15"},
+		{BigToNumeric(nil), ""},
+		{BigToNumeric(big.NewInt(42)), "42"},
+	}
+	for _, tt := range tests {
+		got := NumericString(tt.n)
+		if got != tt.want {
+			t.Errorf("NumericString(%v e%d) = %q, want %q", tt.n.Int, tt.n.Exp, got, tt.want)
+		}
+	}
+}
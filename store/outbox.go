@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/Dev317/golang_wallet/event"
+	"github.com/Dev317/golang_wallet/queue"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	outboxPollInterval = time.Second
+	outboxBatchSize    = 100
+)
+
+// Enqueue writes envelope to the outbox for delivery on queueName. producer
+// tags the row so each service relays only its own events. Callers pass the
+// queries of the transaction that makes the state change the event
+// describes, so either both are committed or neither is.
+func Enqueue(ctx context.Context, q *db.Queries, producer, queueName string, envelope event.Envelope) error {
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateOutboxEvent(ctx, db.CreateOutboxEventParams{
+		Producer:  producer,
+		QueueName: queueName,
+		Payload:   payload,
+	})
+	return err
+}
+
+// RelayOutbox publishes producer's pending outbox events to bus in order,
+// marking each one delivered only once the broker has confirmed it. A failed
+// publish is retried on the next pass, so delivery is at-least-once.
+func (s *Store) RelayOutbox(bus queue.Bus, producer string) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// Events wait in the outbox while the connection is being restored.
+		if bus.State() != queue.StateConnected {
+			continue
+		}
+
+		err := s.relayOutboxBatch(bus, producer)
+		if err != nil {
+			logger.Error("Failed to relay outbox events",
+				slog.String("producer", producer),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// relayOutboxBatch publishes one batch of pending events. Rows are locked for
+// the duration so several relays never publish the same event concurrently.
+func (s *Store) relayOutboxBatch(bus queue.Bus, producer string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var publishErr error
+	err := s.ExecTx(ctx, func(q *db.Queries) error {
+		events, err := q.ListPendingOutboxEvents(ctx, db.ListPendingOutboxEventsParams{
+			Producer: producer,
+			Limit:    outboxBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			err := bus.Publish(ctx, event.QueueName, event.Payload)
+			if err != nil {
+				// Stop here so later events are not delivered ahead of this
+				// one, but commit what was published and the failure itself.
+				publishErr = fmt.Errorf("outbox event %d: %w", event.ID, err)
+				return q.RecordOutboxEventFailure(ctx, db.RecordOutboxEventFailureParams{
+					ID:        event.ID,
+					LastError: pgtype.Text{String: err.Error(), Valid: true},
+				})
+			}
+
+			err = q.MarkOutboxEventPublished(ctx, event.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return publishErr
+}
+
+// MarkProcessed records that consumer handled the event eventID and reports
+// false if it already had, so redelivered events are applied only once.
+// Callers pass the queries of the transaction that applies the event.
+func MarkProcessed(ctx context.Context, q *db.Queries, consumer, eventID string) (bool, error) {
+	_, err := q.MarkEventProcessed(ctx, db.MarkEventProcessedParams{
+		Consumer: consumer,
+		EventID:  eventID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,74 @@
+// Package store holds the database plumbing shared by the services: the
+// connection pool, transactions, and the event outbox and inbox kept in the
+// wallet database.
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	db "github.com/Dev317/golang_wallet/db/wallet/sqlc"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Config names the database to connect to.
+type Config struct {
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+	Host     string
+	Port     string
+}
+
+// ConnString returns the libpq connection string for c.
+func (c Config) ConnString() string {
+	return "user=" + c.User + " password=" + c.Password + " dbname=" + c.Name + " sslmode=" + c.SSLMode + " host=" + c.Host + " port=" + c.Port
+}
+
+// Store is a connection pool to the wallet database and the queries run
+// against it.
+type Store struct {
+	pool *pgxpool.Pool
+	q    *db.Queries
+}
+
+// Open creates the connection pool for config. Connections are made lazily,
+// so an unreachable database surfaces on first use rather than here.
+func Open(config Config) *Store {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, config.ConnString())
+	if err != nil {
+		logger.Error("Failed to create connection pool",
+			slog.Any("error", err),
+		)
+	}
+	return &Store{pool: pool, q: db.New(pool)}
+}
+
+// Queries returns the queries bound to the pool.
+func (s *Store) Queries() *db.Queries {
+	return s.q
+}
+
+// ExecTx runs fn against queries bound to a single database transaction,
+// committing if fn succeeds and rolling back otherwise.
+func (s *Store) ExecTx(ctx context.Context, fn func(*db.Queries) error) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(s.q.WithTx(tx))
+	if err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx error: %v, rollback error: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}